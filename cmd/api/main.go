@@ -11,11 +11,16 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/chenyahui/gin-cache/persist"
+	"github.com/go-redis/redis/v8"
+
 	"github.com/joybiswas007/blog/config"
 	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/publisher"
 	"github.com/joybiswas007/blog/server"
 )
 
@@ -69,12 +74,37 @@ func main() {
 		db.Close()
 	}()
 
-	srv := server.NewServer(db, &cfg, logger)
+	// The response cache, shared by the API and the publisher.
+	redisStore := persist.NewRedisStore(redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Address,
+		Username: cfg.Redis.Username,
+		Password: cfg.Redis.Password,
+	}))
+
+	defer func() {
+		log.Println("Disconnected from redis")
+		redisStore.RedisClient.Close()
+	}()
+
+	// Listen for interrupt signals (SIGINT, SIGTERM), they stop the server and the
+	// background workers.
+	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Publish scheduled posts in the background until the server shuts down.
+	var workers sync.WaitGroup
+	models := database.NewModels(db)
+	interval := time.Duration(cfg.PublishInterval) * time.Second
+	workers.Go(func() {
+		publisher.New(models.Posts, redisStore, logger, interval).Run(sigCtx)
+	})
+
+	srv := server.NewServer(db, redisStore, &cfg, logger)
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
 	// Run graceful shutdown in a separate goroutine
-	go gracefulShutdown(srv, done)
+	go gracefulShutdown(sigCtx, srv, done)
 
 	err = srv.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
@@ -82,14 +112,13 @@ func main() {
 	}
 	// Wait for the graceful shutdown to complete
 	<-done
+
+	// Let the publisher finish its run before the database and redis are closed.
+	workers.Wait()
 	log.Println("Graceful shutdown complete.")
 }
 
-func gracefulShutdown(apiServer *http.Server, done chan bool) {
-	// Listen for interrupt signals (SIGINT, SIGTERM)
-	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
+func gracefulShutdown(sigCtx context.Context, apiServer *http.Server, done chan bool) {
 	// Block until a signal is received
 	<-sigCtx.Done()
 	log.Println("Shutting down gracefully, press Ctrl+C again to force")
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/joybiswas007/blog/config"
 	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/keyring"
 	"github.com/joybiswas007/blog/pkg"
 )
 
@@ -19,8 +21,16 @@ type application struct {
 	configFile      string   // path to configuration file
 	name            string   // full name of the user
 	email           string   // email address for authentication
+	handle          string   // handle of the author profile, derived from the name if empty
 	password        password // password for authentication
+	role            string   // role of the user: admin, editor, author or contributor
+	changeRole      bool     // changeRole updates the role of an existing user
+	invite          bool     // invite creates an invitation link for the email instead of an account
+	disable2FA      bool     // disable2FA turns two-factor authentication off for a locked out user
 	deleteEmptyTags bool     // deleteEmptyTags deletes tags from the database that are not associated with any posts
+	emptyTrash      int      // emptyTrash permanently deletes posts that have been in the trash for more than n days, disabled if negative
+	rotateJWTKey    bool     // rotateJWTKey generates a new JWT signing key, the current one is retired after a grace period
+	jwtAlg          string   // jwtAlg is the algorithm of the key generated by rotateJWTKey: EdDSA or RS256
 }
 
 type password struct {
@@ -38,12 +48,28 @@ func main() {
 		"Your full name (e.g. 'John Smith') for account creation")
 	flag.StringVar(&app.email, "email", "",
 		"Email address to use for your account")
+	flag.StringVar(&app.handle, "handle", "",
+		"URL-safe handle for the author page (default: derived from -name)")
 	flag.StringVar(&app.password.password, "pass", "",
 		"Account password (leave empty to auto-generate a secure password)")
 	flag.BoolVar(&app.password.reset, "reset-pass", false,
 		"Reset user password")
+	flag.StringVar(&app.role, "role", database.RoleAdmin,
+		"User role: admin, editor, author or contributor")
+	flag.BoolVar(&app.changeRole, "change-role", false,
+		"Change the role of an existing user to the one given by -role")
+	flag.BoolVar(&app.invite, "invite", false,
+		"Create a 72 hour invitation link for -email and -role instead of an account with a password")
+	flag.BoolVar(&app.disable2FA, "disable-2fa", false,
+		"Disable two-factor authentication for -email, e.g. after losing the authenticator and recovery codes")
 	flag.BoolVar(&app.deleteEmptyTags, "delete-empty-tags", false,
 		"Delete tags that are not associated with any posts")
+	flag.IntVar(&app.emptyTrash, "empty-trash", -1,
+		"Permanently delete posts that have been in the trash for more than N days (0 empties the whole trash)")
+	flag.BoolVar(&app.rotateJWTKey, "rotate-jwt-key", false,
+		"Generate a new JWT signing key; the current key keeps verifying tokens for jwt.ref_exp hours")
+	flag.StringVar(&app.jwtAlg, "jwt-alg", keyring.EdDSA,
+		"Algorithm of the key generated by -rotate-jwt-key: EdDSA or RS256")
 
 	flag.Parse()
 
@@ -66,7 +92,7 @@ func main() {
 	models := database.NewModels(db)
 
 	if app.deleteEmptyTags {
-		tags, err := models.Tags.GetAll(context.Background())
+		tags, err := models.Tags.GetAll(context.Background(), false)
 		if err != nil {
 			log.Panic(err)
 		}
@@ -115,10 +141,170 @@ func main() {
 		return
 	}
 
+	if app.emptyTrash >= 0 {
+		before := time.Now().AddDate(0, 0, -app.emptyTrash)
+
+		total, err := models.Posts.CountTrash(context.Background(), before)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		if total == 0 {
+			fmt.Printf("No posts in the trash older than %d day(s).\n", app.emptyTrash)
+			return
+		}
+
+		var prompt string
+		fmt.Printf("Found %d trashed post(s) older than %d day(s).\n", total, app.emptyTrash)
+		fmt.Println("Are you sure you want to delete them permanently?yes/y/no/n: ")
+		fmt.Scan(&prompt)
+
+		switch strings.ToLower(prompt) {
+		case "yes", "y":
+			deleted, err := models.Posts.EmptyTrash(context.Background(), before)
+			if err != nil {
+				log.Panic(err)
+			}
+			fmt.Printf("%d trashed posts deleted.\n", deleted)
+		case "no", "n":
+			fmt.Println("No posts were deleted.")
+		default:
+			log.Printf("Unknown prompt type %s", prompt)
+		}
+
+		return
+	}
+
+	if app.rotateJWTKey {
+		key, err := keyring.Generate(app.jwtAlg)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		rec, err := key.Record(cfg.JWT.KeySecret)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		// The old key has to verify refresh tokens until the last one it signed expires.
+		grace := time.Duration(cfg.JWT.RefExp) * time.Hour
+		err = models.SigningKeys.Rotate(context.Background(), rec, grace)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		err = models.AuditLogs.Insert(context.Background(), &database.AuditLog{
+			Action:     database.AuditSigningKeyRotate,
+			TargetType: database.AuditTargetSigningKey,
+			TargetID:   &rec.ID,
+			Details:    map[string]any{"kid": rec.KID, "algorithm": rec.Algorithm, "source": "cli"},
+		})
+		if err != nil {
+			log.Panic(err)
+		}
+
+		fmt.Printf("New %s signing key %s created, running servers pick it up within a minute.\n", key.Algorithm, key.ID)
+		fmt.Printf("The previous key keeps verifying tokens until %s.\n", time.Now().Add(grace).Format(time.RFC1123))
+
+		return
+	}
+
 	if app.email == "" {
 		log.Panic("email can't be empty")
 	}
 
+	if !database.ValidRole(app.role) {
+		log.Panicf("unknown role %q", app.role)
+	}
+
+	if app.invite {
+		// Invitees are authors unless -role is given explicitly.
+		role := database.RoleAuthor
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "role" {
+				role = app.role
+			}
+		})
+
+		token, err := pkg.GenerateToken()
+		if err != nil {
+			log.Panic(err)
+		}
+
+		inv := &database.Invitation{
+			Email:     app.email,
+			Role:      role,
+			ExpiresAt: time.Now().Add(72 * time.Hour),
+		}
+
+		err = models.Invitations.Create(context.Background(), inv, 0, pkg.HashToken(token))
+		if err != nil {
+			log.Panic(err)
+		}
+
+		invitationID := int64(inv.ID)
+		err = models.AuditLogs.Insert(context.Background(), &database.AuditLog{
+			Action:     database.AuditInvitationCreate,
+			TargetType: database.AuditTargetInvitation,
+			TargetID:   &invitationID,
+			Details:    map[string]any{"email": inv.Email, "role": inv.Role, "source": "cli"},
+		})
+		if err != nil {
+			log.Panic(err)
+		}
+
+		fmt.Printf("Invitation for %s (%s) created, it expires at %s.\n", inv.Email, inv.Role, inv.ExpiresAt.Format(time.RFC1123))
+		fmt.Printf("Send them this link: %s/invite/%s\n", strings.TrimRight(cfg.Blog.URL, "/"), token)
+
+		return
+	}
+
+	if app.disable2FA {
+		user, err := models.Users.GetByEmail(context.Background(), app.email)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		if !user.TwoFactorEnabled {
+			fmt.Printf("Two-factor authentication isn't enabled for %s.\n", app.email)
+			return
+		}
+
+		err = models.TwoFactor.Disable(context.Background(), user.ID)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		err = models.AuditLogs.Insert(context.Background(), &database.AuditLog{
+			Action:     database.AuditUser2FADisable,
+			TargetType: database.AuditTargetUser,
+			TargetID:   &user.ID,
+			Details:    map[string]any{"source": "cli"},
+		})
+		if err != nil {
+			log.Panic(err)
+		}
+
+		fmt.Printf("Two-factor authentication disabled for %s, they can sign in with their password alone.\n", app.email)
+
+		return
+	}
+
+	if app.changeRole {
+		user, err := models.Users.GetByEmail(context.Background(), app.email)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		err = models.Users.UpdateRole(context.Background(), user.ID, app.role)
+		if err != nil {
+			log.Panic(err)
+		}
+		fmt.Printf("%s is now %s.\n", app.email, app.role)
+
+		return
+	}
+
 	if app.password.password == "" {
 		app.password.password = pkg.GeneratePassword(30, true, true, true)
 	}
@@ -151,9 +337,15 @@ func main() {
 		log.Panic("name can't be empty")
 	}
 
+	if app.handle != "" && !database.ValidHandle(app.handle) {
+		log.Panic("handle may only contain lowercase letters, digits and dashes, at most 64 of them")
+	}
+
 	user := &database.User{
-		Name:  app.name,
-		Email: app.email,
+		Name:   app.name,
+		Email:  app.email,
+		Handle: app.handle,
+		Role:   app.role,
 	}
 
 	err = user.Password.Set(app.password.password)
@@ -169,5 +361,7 @@ func main() {
 	fmt.Println("Registration successful!")
 	fmt.Println("Email: " + app.email)
 	fmt.Println("Password: " + app.password.password)
+	fmt.Println("Role: " + app.role)
+	fmt.Println("Author page: /authors/" + user.Handle)
 	fmt.Println("Use the ^^ creds for login")
 }
@@ -22,14 +22,20 @@ type Config struct {
 	BuildInfo        Build       // BuildInfo holds build metadata injected via ldflags for version tracking.
 	MaxLoginAttempts int         `mapstructure:"max_login_attempts" validate:"required"` // Max Login Attempts per session
 	BanDuration      int         `mapstructure:"ban_duration" validate:"required"`       // Ban Duration
+	PublishInterval  int         `mapstructure:"publish_interval"`                       // Seconds between scheduled publishing runs (default: 60)
+	Mail             Mail        `mapstructure:"mail"`                                   // Outgoing mail, e.g. password reset links (default: logged)
+	WebAuthn         WebAuthn    `mapstructure:"webauthn"`                               // Passkey sign in (default: bound to the blog URL)
+	Auth             Auth        `mapstructure:"auth"`                                   // How the dashboard keeps users signed in (default: bearer tokens)
 }
 
-// JWT holds configuration related to JSON Web Tokens.
+// JWT holds configuration related to JSON Web Tokens. The secrets are only used until a
+// signing key has been generated, see the keyring package.
 type JWT struct {
 	Secret    string `mapstructure:"secret" validate:"required"`     // Secret key used to sign JWT tokens
 	Exp       int    `mapstructure:"exp" validate:"required"`        // Access token expiration time (in minutes or seconds)
 	RefExp    int    `mapstructure:"ref_exp" validate:"required"`    // Refresh token expiration time
 	RefSecret string `mapstructure:"ref_secret" validate:"required"` // Secret key used to sign refresh JWT tokens
+	KeySecret string `mapstructure:"key_secret"`                     // Secret the stored signing keys are encrypted with
 }
 
 // RateLimiter defines the rate limiting configuration.
@@ -44,6 +50,38 @@ type Blog struct {
 	URL  string `mapstructure:"url" validate:"required"`  // URL of the blog i.e: http(s)://sitename.com
 }
 
+// Mail configures how the blog sends emails.
+type Mail struct {
+	Transport string `mapstructure:"transport" validate:"omitempty,oneof=smtp file log"` // smtp, file or log (default: log)
+	From      string `mapstructure:"from" validate:"omitempty,email"`                    // Sender address (default: no-reply@ the blog host)
+	Dir       string `mapstructure:"dir"`                                                // Directory the file transport writes messages to
+	SMTP      SMTP   `mapstructure:"smtp"`                                               // SMTP server, used by the smtp transport
+}
+
+// SMTP contains the connection configuration for an SMTP server.
+// Username and password may be empty for local servers that don't require auth.
+type SMTP struct {
+	Host     string `mapstructure:"host"`     // SMTP server hostname
+	Port     int    `mapstructure:"port"`     // SMTP server port (default: 587)
+	Username string `mapstructure:"username"` // SMTP auth username
+	Password string `mapstructure:"password"` // SMTP auth password
+}
+
+// WebAuthn configures passkey sign in. Passkeys are bound to the relying party ID, changing
+// it makes every registered passkey unusable.
+type WebAuthn struct {
+	RPID    string   `mapstructure:"rp_id"`   // Domain passkeys are bound to (default: host of the blog URL)
+	Origins []string `mapstructure:"origins"` // Origins the dashboard is served from (default: the blog URL)
+}
+
+// Auth configures how the dashboard keeps users signed in. In cookie mode the tokens are
+// kept in HttpOnly cookies out of reach of scripts, requests with an Authorization header
+// keep using bearer tokens.
+type Auth struct {
+	Mode            string `mapstructure:"mode" validate:"omitempty,oneof=bearer cookie"` // bearer or cookie (default: bearer)
+	RequireAdmin2FA bool   `mapstructure:"require_admin_2fa"`                             // Admins only get their rights once they enabled two-factor auth
+}
+
 // Redis contains the connection configuration for the Redis cache server.
 type Redis struct {
 	Address  string `mapstructure:"address" validate:"required"`  // Redis server address (IP or hostname)
@@ -0,0 +1,47 @@
+// Package cache provides helpers for managing the Redis response cache.
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/chenyahui/gin-cache/persist"
+)
+
+// Invalidate deletes cached data for blog posts, tags, archives, series, and author profiles and
+// feeds by deleting matching keys from Redis.
+func Invalidate(cacheStore *persist.RedisStore) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	patterns := []string{
+		"/api/v1/posts*",           // All post lists
+		"/api/v1/posts/tags",       // Single tag key
+		"/api/v1/posts/archives",   // Single archive key
+		"/api/v1/posts/archives/*", // All archive keys
+		"/api/v1/series/*",         // All series keys
+		"/api/v1/authors/*",        // All author profiles and feeds
+	}
+
+	for _, pattern := range patterns {
+		// For pattern keys, use SCAN.
+		var cursor uint64
+		for {
+			keys, nextCursor, err := cacheStore.RedisClient.Scan(ctx, cursor, pattern, 100).Result()
+			if err != nil {
+				log.Printf("Scan error for pattern %s: %v", pattern, err)
+				break
+			}
+			if len(keys) > 0 {
+				if err := cacheStore.RedisClient.Del(ctx, keys...).Err(); err != nil {
+					log.Printf("Failed to delete keys %v: %v", keys, err)
+				}
+			}
+			cursor = nextCursor
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/chenyahui/gin-cache/persist"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestInvalidate(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	stale := []string{
+		"/api/v1/posts?page=2",
+		"/api/v1/posts/hello-world",
+		"/api/v1/posts/tags",
+		"/api/v1/posts/archives/2024",
+		"/api/v1/series/go",
+		"/api/v1/authors/ada",
+		"/api/v1/authors/ada/rss.xml",
+		"/api/v1/authors/ada/atom.xml",
+	}
+	kept := []string{"webauthn:session", "/api/v1/build-info"}
+
+	for _, key := range append(stale, kept...) {
+		if err := mr.Set(key, "cached"); err != nil {
+			t.Fatalf("Set(%q): %v", key, err)
+		}
+	}
+
+	Invalidate(persist.NewRedisStore(client))
+
+	for _, key := range stale {
+		if mr.Exists(key) {
+			t.Errorf("%q still cached", key)
+		}
+	}
+	for _, key := range kept {
+		if !mr.Exists(key) {
+			t.Errorf("%q was deleted", key)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// accessTokenSeenInterval is how often the last use of a personal access token is
+// recorded, a pipeline calling the API in a loop shouldn't write on every request.
+const accessTokenSeenInterval = time.Minute
+
+// AccessToken is a personal access token. It acts as its user, limited to its scopes.
+// Only the hash of the token is stored.
+type AccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"` // Start of the token, to tell tokens apart
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AccessTokenModel handles database operations for personal access tokens.
+type AccessTokenModel struct {
+	DB Pool // Database connection pool
+}
+
+// Insert stores a new token, filling in its ID and creation time.
+func (m AccessTokenModel) Insert(ctx context.Context, token *AccessToken, tokenHash []byte) error {
+	query := `
+		INSERT INTO personal_access_tokens(user_id, name, token_hash, hint, scopes, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	args := []any{token.UserID, token.Name, tokenHash, token.Hint, token.Scopes, token.ExpiresAt}
+	return m.DB.QueryRow(ctx, query, args...).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetAll retrieves the tokens of a user, expired ones included, newest first.
+func (m AccessTokenModel) GetAll(ctx context.Context, userID int64) ([]*AccessToken, error) {
+	rows, err := m.DB.Query(ctx, `
+		SELECT id, user_id, name, hint, scopes, expires_at, last_used_at, last_used_ip, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*AccessToken{}
+	for rows.Next() {
+		var t AccessToken
+		err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Hint, &t.Scopes, &t.ExpiresAt, &t.LastUsedAt, &t.LastUsedIP, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Authenticate looks up the token with the given hash and records its use from ip.
+// Returns ErrRecordNotFound if the token is unknown or expired.
+func (m AccessTokenModel) Authenticate(ctx context.Context, tokenHash []byte, ip string) (*AccessToken, error) {
+	var t AccessToken
+	err := m.DB.QueryRow(ctx, `
+		SELECT id, user_id, name, hint, scopes, expires_at, last_used_at, last_used_ip, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1 AND expires_at > NOW()`, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.Name, &t.Hint, &t.Scopes, &t.ExpiresAt, &t.LastUsedAt, &t.LastUsedIP, &t.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if t.LastUsedAt != nil && time.Since(*t.LastUsedAt) < accessTokenSeenInterval && t.LastUsedIP == ip {
+		return &t, nil
+	}
+
+	err = m.DB.QueryRow(ctx, `
+		UPDATE personal_access_tokens SET last_used_at = NOW(), last_used_ip = $1
+		WHERE id = $2
+		RETURNING last_used_at`, ip, t.ID).Scan(&t.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.LastUsedIP = ip
+
+	return &t, nil
+}
+
+// Delete revokes a token of userID. Returns ErrRecordNotFound if the user has no such token.
+func (m AccessTokenModel) Delete(ctx context.Context, userID, tokenID int64) error {
+	result, err := m.DB.Exec(ctx, `DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2`, tokenID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteAll revokes every token of userID and returns how many there were.
+func (m AccessTokenModel) DeleteAll(ctx context.Context, userID int64) (int64, error) {
+	result, err := m.DB.Exec(ctx, `DELETE FROM personal_access_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// AutosaveModel handles database operations for autosave buffers.
+type AutosaveModel struct {
+	DB Pool // Database connection pool
+}
+
+// Autosave is the unsaved work of one editor on a post. It's kept apart from the
+// live post until it's applied, so saving often never touches the published version.
+type Autosave struct {
+	PostID      int       `json:"post_id"`               // Post being edited
+	UserID      int64     `json:"-"`                     // Editor the buffer belongs to
+	Title       string    `json:"title"`                 // Title being edited
+	Description *string   `json:"description,omitempty"` // Description being edited
+	Content     string    `json:"content"`               // Content being edited
+	Slug        string    `json:"slug"`                  // Custom slug, empty to derive it from the title
+	Tags        []string  `json:"tags"`                  // Tags being edited, nil if the editor didn't touch them
+	CreatedAt   time.Time `json:"created_at"`            // When the editor started this buffer
+	UpdatedAt   time.Time `json:"updated_at"`            // When the buffer was last saved
+}
+
+// Get retrieves the autosave buffer of a user for a post.
+func (m AutosaveModel) Get(ctx context.Context, postID int, userID int64) (*Autosave, error) {
+	query := `
+		SELECT post_id, user_id, title, description, content, slug, tags, created_at, updated_at
+		FROM post_autosaves
+		WHERE post_id = $1 AND user_id = $2`
+
+	var a Autosave
+	err := m.DB.QueryRow(ctx, query, postID, userID).Scan(
+		&a.PostID,
+		&a.UserID,
+		&a.Title,
+		&a.Description,
+		&a.Content,
+		&a.Slug,
+		&a.Tags,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &a, nil
+}
+
+// Save creates or replaces the autosave buffer of a user for a post.
+// CreatedAt and UpdatedAt are filled in from the database.
+func (m AutosaveModel) Save(ctx context.Context, a *Autosave) error {
+	query := `
+		INSERT INTO post_autosaves(post_id, user_id, title, description, content, slug, tags)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (post_id, user_id) DO UPDATE
+		SET title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    content = EXCLUDED.content,
+		    slug = EXCLUDED.slug,
+		    tags = EXCLUDED.tags,
+		    updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	args := []any{a.PostID, a.UserID, a.Title, a.Description, a.Content, a.Slug, a.Tags}
+
+	err := m.DB.QueryRow(ctx, query, args...).Scan(&a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		if pgErrorCode(err) == pgForeignKeyViolation {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete throws away the autosave buffer of a user for a post.
+func (m AutosaveModel) Delete(ctx context.Context, postID int, userID int64) error {
+	query := `DELETE FROM post_autosaves WHERE post_id = $1 AND user_id = $2`
+
+	result, err := m.DB.Exec(ctx, query, postID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
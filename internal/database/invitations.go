@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gosimple/slug"
+)
+
+// InvitationModel handles database operations for user invitations.
+type InvitationModel struct {
+	DB Pool // Database connection pool
+}
+
+// Invitation lets the holder of its token create an account with the given email and role once.
+// Only the hash of the token is stored, the token itself is shown once when the invitation is created.
+type Invitation struct {
+	ID         int        `json:"id"`                    // Unique identifier for the invitation
+	Email      string     `json:"email"`                 // Email address the account will use
+	Role       string     `json:"role"`                  // Role the account will get
+	InvitedBy  *string    `json:"invited_by"`            // Name of the user who sent the invitation, if they still exist
+	ExpiresAt  time.Time  `json:"expires_at"`            // When the invitation stops working
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"` // When the invitation was used
+	CreatedAt  time.Time  `json:"created_at"`            // When the invitation was created
+}
+
+// Create stores a new invitation and fills in its ID and creation time.
+func (m InvitationModel) Create(ctx context.Context, inv *Invitation, invitedBy int64, tokenHash []byte) error {
+	query := `INSERT INTO invitations(email, role, token_hash, invited_by, expires_at)
+		  VALUES($1, $2, $3, NULLIF($4, 0), $5)
+		  RETURNING id, created_at`
+
+	args := []any{inv.Email, inv.Role, tokenHash, invitedBy, inv.ExpiresAt}
+
+	return m.DB.QueryRow(ctx, query, args...).Scan(&inv.ID, &inv.CreatedAt)
+}
+
+// GetAll retrieves the invitations that are still pending, newest first.
+func (m InvitationModel) GetAll(ctx context.Context) ([]*Invitation, error) {
+	query := `
+		SELECT i.id, i.email, i.role, u.name, i.expires_at, i.accepted_at, i.created_at
+		FROM invitations i
+		LEFT JOIN users u ON i.invited_by = u.id
+		WHERE i.accepted_at IS NULL AND i.expires_at > NOW()
+		ORDER BY i.id DESC`
+
+	rows, err := m.DB.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*Invitation
+	for rows.Next() {
+		var inv Invitation
+		err := rows.Scan(&inv.ID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt, &inv.AcceptedAt, &inv.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, &inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// GetByToken retrieves a pending invitation by the hash of its token.
+// Returns ErrRecordNotFound if the token is unknown, used, revoked or expired.
+func (m InvitationModel) GetByToken(ctx context.Context, tokenHash []byte) (*Invitation, error) {
+	query := `
+		SELECT i.id, i.email, i.role, u.name, i.expires_at, i.accepted_at, i.created_at
+		FROM invitations i
+		LEFT JOIN users u ON i.invited_by = u.id
+		WHERE i.token_hash = $1 AND i.accepted_at IS NULL AND i.expires_at > NOW()`
+
+	var inv Invitation
+	err := m.DB.QueryRow(ctx, query, tokenHash).Scan(
+		&inv.ID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &inv, nil
+}
+
+// Accept uses up a pending invitation and creates the account for it. The email and
+// role come from the invitation, the name, handle and password from user, which is
+// filled in with the new account.
+func (m InvitationModel) Accept(ctx context.Context, tokenHash []byte, user *User) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the invitation so it can't be accepted twice at the same time.
+	var invitationID int
+	err = tx.QueryRow(ctx, `
+		SELECT id, email, role
+		FROM invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		FOR UPDATE`, tokenHash).Scan(&invitationID, &user.Email, &user.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	if user.Handle == "" {
+		user.Handle = slug.Make(user.Name)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (name, email, password_hash, role, handle)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`,
+		user.Name, user.Email, user.Password.hash, user.Role, user.Handle,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return duplicateUserError(err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE invitations SET accepted_at = NOW() WHERE id = $1`, invitationID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete revokes a pending invitation.
+func (m InvitationModel) Delete(ctx context.Context, invitationID int) error {
+	query := `DELETE FROM invitations WHERE id = $1 AND accepted_at IS NULL`
+
+	result, err := m.DB.Exec(ctx, query, invitationID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
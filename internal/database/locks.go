@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LockModel handles database operations for advisory post edit locks.
+type LockModel struct {
+	DB Pool // Database connection pool
+}
+
+// Lock tells editors that someone else is working on a post. Locks are advisory,
+// they never block an update, and expire unless the holder keeps refreshing them.
+type Lock struct {
+	PostID    int       `json:"post_id"`    // Locked post
+	UserID    int64     `json:"user_id"`    // User holding the lock
+	Name      string    `json:"name"`       // Name of the user holding the lock
+	ExpiresAt time.Time `json:"expires_at"` // When the lock lapses unless refreshed
+	CreatedAt time.Time `json:"created_at"` // When the user started editing
+}
+
+// Get retrieves the active lock of a post. Returns ErrRecordNotFound if nobody holds it.
+func (m LockModel) Get(ctx context.Context, postID int) (*Lock, error) {
+	query := `
+		SELECT pl.post_id, pl.user_id, u.name, pl.expires_at, pl.created_at
+		FROM post_locks pl
+		JOIN users u ON pl.user_id = u.id
+		WHERE pl.post_id = $1 AND pl.expires_at > NOW()`
+
+	var l Lock
+	err := m.DB.QueryRow(ctx, query, postID).Scan(&l.PostID, &l.UserID, &l.Name, &l.ExpiresAt, &l.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &l, nil
+}
+
+// Acquire takes or refreshes the lock of a post for userID for the given duration.
+// If another user holds an active lock, their lock is returned along with ErrPostLocked.
+func (m LockModel) Acquire(ctx context.Context, postID int, userID int64, ttl time.Duration) (*Lock, error) {
+	query := `
+		INSERT INTO post_locks(post_id, user_id, expires_at)
+		VALUES($1, $2, NOW() + $3 * INTERVAL '1 second')
+		ON CONFLICT (post_id) DO UPDATE
+		SET user_id = EXCLUDED.user_id,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = CASE WHEN post_locks.user_id = EXCLUDED.user_id THEN post_locks.created_at ELSE NOW() END
+		WHERE post_locks.user_id = EXCLUDED.user_id OR post_locks.expires_at <= NOW()`
+
+	result, err := m.DB.Exec(ctx, query, postID, userID, int(ttl.Seconds()))
+	if err != nil {
+		if pgErrorCode(err) == pgForeignKeyViolation {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	lock, err := m.Get(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return lock, ErrPostLocked
+	}
+	return lock, nil
+}
+
+// Release drops the lock userID holds on a post.
+func (m LockModel) Release(ctx context.Context, postID int, userID int64) error {
+	query := `DELETE FROM post_locks WHERE post_id = $1 AND user_id = $2`
+
+	result, err := m.DB.Exec(ctx, query, postID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,13 +19,65 @@ var (
 
 	// ErrDuplicateEmail is returned when attempting to create a user with an existing email.
 	ErrDuplicateEmail = errors.New("duplicate email")
+
+	// ErrDuplicateHandle is returned when a user handle is already taken.
+	ErrDuplicateHandle = errors.New("duplicate handle")
+
+	// ErrDuplicateSlug is returned when a slug is already used by another record.
+	ErrDuplicateSlug = errors.New("duplicate slug")
+
+	// ErrPostLocked is returned when another user is editing a post.
+	ErrPostLocked = errors.New("post is being edited by another user")
+
+	// ErrUserHasPosts is returned when deleting a user would delete their posts too.
+	ErrUserHasPosts = errors.New("user still owns posts")
+
+	// ErrUnknownAuthor is returned when a post author doesn't match any user.
+	ErrUnknownAuthor = errors.New("unknown author")
+
+	// ErrTwoFactorEnabled is returned when starting or confirming two-factor enrollment
+	// for a user who already has it on.
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+
+	// ErrRefreshTokenReused is returned when a refresh token that was already rotated is
+	// used again. The session it belongs to has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+
+	// ErrNotDraft is returned when scheduling a post that isn't a draft.
+	ErrNotDraft = errors.New("only drafts can be scheduled")
+
+	// ErrPostInAnotherSeries is returned when adding a post that already belongs to a different series.
+	ErrPostInAnotherSeries = errors.New("post already belongs to another series")
 )
 
+// Pool is the part of a *pgxpool.Pool the models use, tests stand in a mock for it.
+type Pool interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // Models contains all database models.
 type Models struct {
-	Posts PostModel
-	Tags  TagModel
-	Users UserModel
+	AccessTokens   AccessTokenModel
+	AuditLogs      AuditLogModel
+	Autosaves      AutosaveModel
+	Invitations    InvitationModel
+	Locks          LockModel
+	Passkeys       PasskeyModel
+	PasswordResets PasswordResetModel
+	Posts          PostModel
+	Previews       PreviewModel
+	RefreshTokens  RefreshTokenModel
+	Revisions      RevisionModel
+	Series         SeriesModel
+	Sessions       SessionModel
+	SigningKeys    SigningKeyModel
+	Tags           TagModel
+	TwoFactor      TwoFactorModel
+	UnlockAttempts UnlockAttemptModel
+	Users          UserModel
 }
 
 // Filter contains query filtering options.
@@ -33,9 +87,37 @@ type Filter struct {
 	Tag         string // Search via specific tag
 	OrderBy     string // Column to order by
 	Sort        string // Sort direction (ASC/DESC)
-	IsPublished bool   // Filter by published status: true = published, false = drafts
+	IsPublished bool   // Filter by published status: true = published, false = everything else
+	Status      string // Filter by post status (draft, scheduled, unlisted, private, published); empty matches all
+	AuthorID    int64  // Filter by author, co-authored posts included; zero matches all
 }
 
+// pgErrorCode returns the PostgreSQL error code of err, or an empty string if err
+// didn't come from the server.
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// pgConstraintName returns the name of the constraint err violated, or an empty string
+// if err didn't come from the server.
+func pgConstraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
+
+// PostgreSQL error codes checked by the models.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
 // New creates a new database connection pool.
 func New(connStr string) (*pgxpool.Pool, error) {
 	// Parse connection string into pool configuration
@@ -54,10 +136,25 @@ func New(connStr string) (*pgxpool.Pool, error) {
 }
 
 // NewModels initializes all database models with the given connection pool.
-func NewModels(pool *pgxpool.Pool) Models {
+func NewModels(pool Pool) Models {
 	return Models{
-		Posts: PostModel{DB: pool},
-		Tags:  TagModel{DB: pool},
-		Users: UserModel{DB: pool},
+		AccessTokens:   AccessTokenModel{DB: pool},
+		AuditLogs:      AuditLogModel{DB: pool},
+		Autosaves:      AutosaveModel{DB: pool},
+		Invitations:    InvitationModel{DB: pool},
+		Locks:          LockModel{DB: pool},
+		Passkeys:       PasskeyModel{DB: pool},
+		PasswordResets: PasswordResetModel{DB: pool},
+		Posts:          PostModel{DB: pool},
+		Previews:       PreviewModel{DB: pool},
+		RefreshTokens:  RefreshTokenModel{DB: pool},
+		Revisions:      RevisionModel{DB: pool},
+		Series:         SeriesModel{DB: pool},
+		Sessions:       SessionModel{DB: pool},
+		SigningKeys:    SigningKeyModel{DB: pool},
+		Tags:           TagModel{DB: pool},
+		TwoFactor:      TwoFactorModel{DB: pool},
+		UnlockAttempts: UnlockAttemptModel{DB: pool},
+		Users:          UserModel{DB: pool},
 	}
 }
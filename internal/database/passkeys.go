@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PasskeyModel handles database operations for WebAuthn credentials.
+type PasskeyModel struct {
+	DB Pool // Database connection pool
+}
+
+// Passkey is a WebAuthn credential registered by a user. Only the public key is stored,
+// the private key never leaves the authenticator.
+type Passkey struct {
+	ID                int64      `json:"id"`              // Unique identifier for the passkey
+	UserID            int64      `json:"-"`               // Owner of the passkey
+	Name              string     `json:"name"`            // Label chosen by the user, e.g. "Laptop"
+	CredentialID      []byte     `json:"-"`               // Credential ID assigned by the authenticator
+	PublicKey         []byte     `json:"-"`               // COSE encoded public key
+	AttestationType   string     `json:"-"`               // Attestation type seen at registration
+	AttestationFormat string     `json:"-"`               // Attestation statement format seen at registration
+	AAGUID            []byte     `json:"-"`               // Model of the authenticator
+	SignCount         int64      `json:"sign_count"`      // Last signature counter reported by the authenticator
+	Transports        []string   `json:"transports"`      // How the browser can reach the authenticator, e.g. usb or internal
+	UserVerified      bool       `json:"-"`               // Whether the authenticator verified the user, e.g. with a PIN
+	BackupEligible    bool       `json:"backup_eligible"` // Whether the passkey can be synced between devices
+	BackupState       bool       `json:"backup_state"`    // Whether the passkey is currently synced
+	CreatedAt         time.Time  `json:"created_at"`      // When the passkey was registered
+	LastUsedAt        *time.Time `json:"last_used_at"`    // When the passkey was last used to sign in
+}
+
+// Insert stores a newly registered passkey and fills in its ID and creation time.
+func (m PasskeyModel) Insert(ctx context.Context, pk *Passkey) error {
+	query := `
+		INSERT INTO passkeys (user_id, name, credential_id, public_key, attestation_type, attestation_format,
+		                      aaguid, sign_count, transports, user_verified, backup_eligible, backup_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at`
+
+	if pk.Transports == nil {
+		pk.Transports = []string{}
+	}
+
+	args := []any{
+		pk.UserID, pk.Name, pk.CredentialID, pk.PublicKey, pk.AttestationType, pk.AttestationFormat,
+		pk.AAGUID, pk.SignCount, pk.Transports, pk.UserVerified, pk.BackupEligible, pk.BackupState,
+	}
+
+	err := m.DB.QueryRow(ctx, query, args...).Scan(&pk.ID, &pk.CreatedAt)
+	if err != nil {
+		if pgErrorCode(err) == pgForeignKeyViolation {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetAll retrieves all passkeys of a user, oldest first.
+func (m PasskeyModel) GetAll(ctx context.Context, userID int64) ([]*Passkey, error) {
+	query := `
+		SELECT id, user_id, name, credential_id, public_key, attestation_type, attestation_format,
+		       aaguid, sign_count, transports, user_verified, backup_eligible, backup_state, created_at, last_used_at
+		FROM passkeys
+		WHERE user_id = $1
+		ORDER BY id ASC`
+
+	rows, err := m.DB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passkeys := []*Passkey{}
+	for rows.Next() {
+		var pk Passkey
+		err := rows.Scan(
+			&pk.ID,
+			&pk.UserID,
+			&pk.Name,
+			&pk.CredentialID,
+			&pk.PublicKey,
+			&pk.AttestationType,
+			&pk.AttestationFormat,
+			&pk.AAGUID,
+			&pk.SignCount,
+			&pk.Transports,
+			&pk.UserVerified,
+			&pk.BackupEligible,
+			&pk.BackupState,
+			&pk.CreatedAt,
+			&pk.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, &pk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return passkeys, nil
+}
+
+// UpdateUsage records a sign in with a passkey: the new signature counter and flags.
+func (m PasskeyModel) UpdateUsage(ctx context.Context, pk *Passkey) error {
+	query := `
+		UPDATE passkeys
+		SET sign_count = $1, user_verified = $2, backup_state = $3, last_used_at = NOW()
+		WHERE id = $4
+		RETURNING last_used_at`
+
+	err := m.DB.QueryRow(ctx, query, pk.SignCount, pk.UserVerified, pk.BackupState, pk.ID).Scan(&pk.LastUsedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes a passkey of a user.
+func (m PasskeyModel) Delete(ctx context.Context, userID, passkeyID int64) error {
+	result, err := m.DB.Exec(ctx, `DELETE FROM passkeys WHERE id = $1 AND user_id = $2`, passkeyID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PasswordResetModel handles database operations for password reset links.
+type PasswordResetModel struct {
+	DB Pool // Database connection pool
+}
+
+// Create stores a new reset token for a user. Older unused tokens of the user stop
+// working, only the latest link sent out can be used.
+func (m PasswordResetModel) Create(ctx context.Context, userID int64, tokenHash []byte, expiresAt time.Time) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM password_resets WHERE user_id = $1 AND used_at IS NULL`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO password_resets(user_id, token_hash, expires_at) VALUES($1, $2, $3)`,
+		userID, tokenHash, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Consume uses up a reset token and sets the password of its user to the one in
+// user, whose ID is filled in. Whoever knew the old password may still be signed in
+// or hold an access token, so in the same transaction all sessions of the user are
+// revoked and the access tokens deleted; their number is returned. Returns
+// ErrRecordNotFound if the token is unknown, used or expired.
+func (m PasswordResetModel) Consume(ctx context.Context, tokenHash []byte, user *User) (int64, error) {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the token so it can't be used twice at the same time.
+	var resetID int
+	err = tx.QueryRow(ctx, `
+		SELECT id, user_id
+		FROM password_resets
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		FOR UPDATE`, tokenHash).Scan(&resetID, &user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, user.Password.hash, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE password_resets SET used_at = NOW() WHERE id = $1`, resetID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL`, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM personal_access_tokens WHERE user_id = $1`, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), tx.Commit(ctx)
+}
@@ -7,28 +7,70 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 )
 
 // PostModel handles database operations for blog posts.
 type PostModel struct {
-	DB *pgxpool.Pool // Database connection pool
+	DB Pool // Database connection pool
 }
 
+// authorsColumn selects the authors of the post aliased bp, in byline order, as a JSON
+// array scanned into Post.Authors.
+const authorsColumn = `COALESCE((
+	SELECT JSON_AGG(JSON_BUILD_OBJECT('id', au.id, 'name', au.name, 'handle', au.handle) ORDER BY pa.position)
+	FROM post_authors pa
+	JOIN users au ON au.id = pa.user_id
+	WHERE pa.post_id = bp.id
+), '[]') AS authors`
+
 // Post represents a blog post in the database.
 type Post struct {
-	ID          int       `json:"id"`                    // Unique identifier for the post
-	UserID      int64     `json:"-"`                     // UserID of the user who created the post
-	Author      string    `json:"author"`                // Author the guy who posted the blog
-	Title       string    `json:"title"`                 // Title of the post
-	Description *string   `json:"description,omitempty"` // Short summary of the post
-	Content     string    `json:"content" `              // Main content of the post
-	Slug        string    `json:"slug"`                  // Slug of post title
-	IsPublished bool      `json:"is_published"`          // Indicates if the post is published or not
-	Tags        []string  `json:"tags"`                  // List of tags associated with the post
-	Views       int64     `json:"-"`                     // Views tracks the number of times a post has been viewed.
-	CreatedAt   time.Time `json:"created_at"`            // When the post was created
-	UpdatedAt   time.Time `json:"updated_at"`            // When the post was last updated
+	ID              int        `json:"id"`                     // Unique identifier for the post
+	UserID          int64      `json:"-"`                      // UserID of the user who created the post
+	Author          string     `json:"author"`                 // Author the guy who posted the blog
+	Authors         []Author   `json:"authors"`                // Authors of the post in byline order
+	Title           string     `json:"title"`                  // Title of the post
+	Description     *string    `json:"description,omitempty"`  // Short summary of the post
+	Content         string     `json:"content" `               // Main content of the post
+	Slug            string     `json:"slug"`                   // Slug of post title
+	IsPublished     bool       `json:"is_published"`           // Indicates if the post is published or not
+	Visibility      string     `json:"visibility"`             // Who can see the post: draft, unlisted, private or published
+	IsProtected     bool       `json:"is_protected"`           // Whether readers need a password to see the content
+	PasswordVersion int        `json:"-"`                      // Incremented whenever the password changes, unlock tokens carry it
+	Status          string     `json:"status"`                 // Status of the post: its visibility, or scheduled for drafts waiting to go live
+	PublishAt       *time.Time `json:"publish_at,omitempty"`   // When a scheduled draft should go live
+	PublishedAt     *time.Time `json:"published_at,omitempty"` // When the post went live
+	Tags            []string   `json:"tags"`                   // List of tags associated with the post
+	Views           int64      `json:"-"`                      // Views tracks the number of times a post has been viewed.
+	CreatedAt       time.Time  `json:"created_at"`             // When the post was created
+	UpdatedAt       time.Time  `json:"updated_at"`             // When the post was last updated
+	Version         int        `json:"version"`                // Incremented on every update, guards against overwriting concurrent edits
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`   // When the post was moved to the trash
+}
+
+// Post visibility values stored in Post.Visibility.
+const (
+	VisibilityDraft     = "draft"     // Work in progress, only visible to admins
+	VisibilityUnlisted  = "unlisted"  // Reachable by slug, hidden from lists, feeds, the sitemap and search
+	VisibilityPrivate   = "private"   // Taken down, only visible to admins
+	VisibilityPublished = "published" // Live post
+)
+
+// Post status values reported in Post.Status.
+const (
+	StatusDraft     = VisibilityDraft     // Draft without a schedule
+	StatusScheduled = "scheduled"         // Draft waiting for its publish_at time
+	StatusUnlisted  = VisibilityUnlisted  // Unlisted post
+	StatusPrivate   = VisibilityPrivate   // Private post
+	StatusPublished = VisibilityPublished // Live post
+)
+
+// Author is a user credited in the byline of a post.
+type Author struct {
+	ID     int64  `json:"id"`     // ID of the user
+	Name   string `json:"name"`   // Full name of the user
+	Handle string `json:"handle"` // Handle of the author profile
 }
 
 // TopPost represents a simplified blog post for top posts api responses.
@@ -55,17 +97,28 @@ type YearlyStats struct {
 
 // Get retrieves a single post by its ID including associated tags.
 func (m PostModel) Get(ctx context.Context, postID int) (*Post, error) {
-	query := `
+	query := fmt.Sprintf(`
         SELECT
             bp.id,
+            bp.user_id,
 	    u.name AS author,
+            %s,
             bp.title,
 	    bp.description,
             bp.content,
 	    bp.slug,
-	    bp.is_published,
+	    bp.visibility = 'published' AS is_published,
+	    bp.visibility,
+	    bp.password_hash IS NOT NULL AS is_protected,
+            CASE
+                WHEN bp.visibility = 'draft' AND bp.publish_at IS NOT NULL THEN 'scheduled'
+                ELSE bp.visibility
+            END AS status,
+            bp.publish_at,
+            bp.published_at,
             bp.created_at,
             bp.updated_at,
+            bp.version,
             COALESCE(ARRAY_AGG(t.name), '{}') AS tags
         FROM
             blog_posts bp
@@ -77,21 +130,30 @@ func (m PostModel) Get(ctx context.Context, postID int) (*Post, error) {
             tags t ON t.id = bt.tag_id
         WHERE
             bp.id = $1
+            AND bp.deleted_at IS NULL
         GROUP BY
             bp.id, u.name;
-    `
+    `, authorsColumn)
 
 	var p Post
 	err := m.DB.QueryRow(ctx, query, postID).Scan(
 		&p.ID,
+		&p.UserID,
 		&p.Author,
+		&p.Authors,
 		&p.Title,
 		&p.Description,
 		&p.Content,
 		&p.Slug,
 		&p.IsPublished,
+		&p.Visibility,
+		&p.IsProtected,
+		&p.Status,
+		&p.PublishAt,
+		&p.PublishedAt,
 		&p.CreatedAt,
 		&p.UpdatedAt,
+		&p.Version,
 		&p.Tags,
 	)
 	if err != nil {
@@ -106,13 +168,14 @@ func (m PostModel) Get(ctx context.Context, postID int) (*Post, error) {
 	return &p, nil
 }
 
-// Exists checks if a blog post with the given slug already exists in the database.
+// Exists checks if a blog post other than excludeID already uses the given slug. Pass
+// zero as excludeID to check every post, e.g. before creating one.
 // Returns true if it exists, false otherwise, or an error on failure.
-func (m PostModel) Exists(ctx context.Context, slug string) (exists bool, err error) {
+func (m PostModel) Exists(ctx context.Context, slug string, excludeID int) (exists bool, err error) {
 	// SQL query to efficiently check existence by slug
-	query := `SELECT EXISTS(SELECT 1 FROM blog_posts WHERE slug = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM blog_posts WHERE slug = $1 AND id <> $2)`
 
-	err = m.DB.QueryRow(ctx, query, slug).Scan(&exists)
+	err = m.DB.QueryRow(ctx, query, slug, excludeID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -120,19 +183,30 @@ func (m PostModel) Exists(ctx context.Context, slug string) (exists bool, err er
 	return exists, nil
 }
 
-// GetBySlug retrieves a single post by its slug including author name and associated tags.
+// GetBySlug retrieves a single published or unlisted post by its slug including author name and associated tags.
 func (m PostModel) GetBySlug(ctx context.Context, slug string) (*Post, error) {
-	query := `
+	query := fmt.Sprintf(`
         SELECT
             bp.id,
             u.name AS author,
+            %s,
             bp.title,
 	    bp.description,
             bp.content,
             bp.slug,
-	    bp.is_published,
+	    bp.visibility = 'published' AS is_published,
+	    bp.visibility,
+	    bp.password_hash IS NOT NULL AS is_protected,
+	    bp.password_version,
+            CASE
+                WHEN bp.visibility = 'draft' AND bp.publish_at IS NOT NULL THEN 'scheduled'
+                ELSE bp.visibility
+            END AS status,
+            bp.publish_at,
+            bp.published_at,
             bp.created_at,
             bp.updated_at,
+            bp.version,
             COALESCE(ARRAY_AGG(t.name), '{}') AS tags
         FROM
             blog_posts bp
@@ -144,22 +218,31 @@ func (m PostModel) GetBySlug(ctx context.Context, slug string) (*Post, error) {
             tags t ON t.id = bt.tag_id
         WHERE
             bp.slug = $1
-	    AND bp.is_published = true
+	    AND bp.visibility IN ('published', 'unlisted')
+	    AND bp.deleted_at IS NULL
         GROUP BY
             bp.id, u.name;
-    `
+    `, authorsColumn)
 
 	var p Post
 	err := m.DB.QueryRow(ctx, query, slug).Scan(
 		&p.ID,
 		&p.Author,
+		&p.Authors,
 		&p.Title,
 		&p.Description,
 		&p.Content,
 		&p.Slug,
 		&p.IsPublished,
+		&p.Visibility,
+		&p.IsProtected,
+		&p.PasswordVersion,
+		&p.Status,
+		&p.PublishAt,
+		&p.PublishedAt,
 		&p.CreatedAt,
 		&p.UpdatedAt,
+		&p.Version,
 		&p.Tags,
 	)
 	if err != nil {
@@ -174,14 +257,62 @@ func (m PostModel) GetBySlug(ctx context.Context, slug string) (*Post, error) {
 	return &p, nil
 }
 
-// Create inserts a new blog post and returns its ID.
-func (m PostModel) Create(ctx context.Context, p *Post) (int, error) {
+// GetRedirectSlug returns the current slug of the published or unlisted post that used to be
+// reachable under oldSlug. Returns ErrRecordNotFound if oldSlug was never retired.
+func (m PostModel) GetRedirectSlug(ctx context.Context, oldSlug string) (string, error) {
+	query := `
+		SELECT bp.slug
+		FROM post_slugs ps
+		JOIN blog_posts bp ON bp.id = ps.post_id
+		WHERE ps.slug = $1
+		  AND bp.visibility IN ('published', 'unlisted')
+		  AND bp.deleted_at IS NULL`
+
+	var slug string
+	err := m.DB.QueryRow(ctx, query, oldSlug).Scan(&slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		default:
+			return "", err
+		}
+	}
+
+	return slug, nil
+}
+
+// Create inserts a new blog post and returns its ID. The owner is the only author
+// unless authorIDs lists the authors in byline order.
+func (m PostModel) Create(ctx context.Context, p *Post, authorIDs ...int64) (int, error) {
+	if len(authorIDs) == 0 {
+		authorIDs = []int64{p.UserID}
+	}
+
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
 	var postID int
-	query := `INSERT INTO blog_posts(user_id, title, description, content, slug, is_published) 
-		  VALUES($1, $2, $3, $4, $5, $6) 
+	query := `INSERT INTO blog_posts(user_id, title, description, content, slug, visibility, publish_at, published_at) 
+		  VALUES($1, $2, $3, $4, $5, $6, $7, CASE WHEN $6 IN ('published', 'unlisted') THEN NOW() END) 
 		  RETURNING id`
 
-	err := m.DB.QueryRow(ctx, query, p.UserID, p.Title, p.Description, p.Content, p.Slug, p.IsPublished).Scan(&postID)
+	args := []any{p.UserID, p.Title, p.Description, p.Content, p.Slug, p.Visibility, p.PublishAt}
+
+	err = tx.QueryRow(ctx, query, args...).Scan(&postID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = setAuthors(ctx, tx, postID, authorIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -189,6 +320,28 @@ func (m PostModel) Create(ctx context.Context, p *Post) (int, error) {
 	return postID, nil
 }
 
+// setAuthors replaces the authors of a post within tx.
+func setAuthors(ctx context.Context, tx pgx.Tx, postID int, authorIDs []int64) error {
+	_, err := tx.Exec(ctx, `DELETE FROM post_authors WHERE post_id = $1`, postID)
+	if err != nil {
+		return err
+	}
+
+	for i, userID := range authorIDs {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO post_authors(post_id, user_id, position) VALUES($1, $2, $3) ON CONFLICT DO NOTHING`,
+			postID, userID, i)
+		if err != nil {
+			if pgErrorCode(err) == pgForeignKeyViolation {
+				return ErrUnknownAuthor
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // AddTag associates a tag with a blog post.
 func (m PostModel) AddTag(ctx context.Context, postID, tagID int) error {
 	query := `INSERT INTO blog_tag(blog_id, tag_id) VALUES($1, $2)`
@@ -203,30 +356,75 @@ func (m PostModel) AddTag(ctx context.Context, postID, tagID int) error {
 	return nil
 }
 
-// Update updates the specific post.
-func (m PostModel) Update(ctx context.Context, p *Post) error {
+// Update updates the specific post and records the result as a revision authored by editorID.
+// The first update of a post also records the original version so nothing is lost.
+// If authorIDs are given they replace the authors of the post, in byline order.
+// If p.Version is set, the update only goes through if the post is still at that version,
+// otherwise ErrEditConflict is returned. On success p.Version holds the new version.
+// With schedule set, p.PublishAt becomes the time at which the draft is published
+// automatically, a nil PublishAt cancels the schedule. ErrNotDraft is returned if the
+// post isn't a draft.
+func (m PostModel) Update(ctx context.Context, p *Post, editorID int64, schedule bool, authorIDs ...int64) error {
 	tx, err := m.DB.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	// First verify the post exists.
-	var exists bool
-	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM blog_posts WHERE id = $1)`, p.ID).Scan(&exists)
+	// First verify the post exists, locking it until the update is done.
+	var version int
+	err = tx.QueryRow(ctx,
+		`SELECT version FROM blog_posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		p.ID).Scan(&version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	// Someone else saved the post since the editor loaded it.
+	if p.Version != 0 && p.Version != version {
+		return ErrEditConflict
+	}
+
+	// Remember the old slug so links to it keep working after a rename.
+	var oldSlug string
+	err = tx.QueryRow(ctx, `SELECT slug FROM blog_posts WHERE id = $1`, p.ID).Scan(&oldSlug)
 	if err != nil {
 		return err
 	}
 
-	if !exists {
-		return ErrRecordNotFound
+	if oldSlug != p.Slug {
+		// The new slug is live again, it must not redirect anywhere.
+		_, err = tx.Exec(ctx, `DELETE FROM post_slugs WHERE slug = $1`, p.Slug)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO post_slugs(slug, post_id) VALUES($1, $2)
+			ON CONFLICT (slug) DO UPDATE SET post_id = EXCLUDED.post_id, created_at = NOW()`,
+			oldSlug, p.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Keep the version we're about to overwrite if it was never recorded.
+	err = snapshotOriginal(ctx, tx, p.ID)
+	if err != nil {
+		return err
 	}
 
 	// update the blog post.
 	updateQuery := `
             UPDATE blog_posts 
-            SET title = $1, description = $2, content = $3, slug = $4
-            WHERE id = $5`
+            SET title = $1, description = $2, content = $3, slug = $4, version = version + 1
+            WHERE id = $5
+            RETURNING version`
 
 	updateArgs := []any{
 		p.Title,
@@ -235,11 +433,23 @@ func (m PostModel) Update(ctx context.Context, p *Post) error {
 		p.Slug,
 		p.ID, // id we are passing to identify the post.
 	}
-	_, err = tx.Exec(ctx, updateQuery, updateArgs...)
+	err = tx.QueryRow(ctx, updateQuery, updateArgs...).Scan(&version)
 	if err != nil {
 		return err
 	}
 
+	if schedule {
+		result, err := tx.Exec(ctx,
+			`UPDATE blog_posts SET publish_at = $1 WHERE id = $2 AND visibility = 'draft'`,
+			p.PublishAt, p.ID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrNotDraft
+		}
+	}
+
 	if p.Tags != nil {
 		// Delete existing tag mappings.
 		_, err = tx.Exec(ctx, `DELETE FROM blog_tag WHERE blog_id = $1`, p.ID)
@@ -279,33 +489,163 @@ func (m PostModel) Update(ctx context.Context, p *Post) error {
 
 		}
 	}
+
+	if len(authorIDs) > 0 {
+		err = setAuthors(ctx, tx, p.ID, authorIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Record the new version.
+	err = snapshotPost(ctx, tx, p.ID, editorID)
+	if err != nil {
+		return err
+	}
+
 	// Commit transaction.
-	return tx.Commit(ctx)
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.Version = version
+	return nil
 }
 
-// Publish sets the `is_published` field of a post to true, marking it as published.
-// Returns an error if the post doesn't exist or the update fails.
-func (m PostModel) Publish(ctx context.Context, postID int) error {
-	query := `UPDATE blog_posts 
-          SET is_published = true,
-		created_at = NOW()
-          WHERE id = $1`
+// SetPassword protects a post with a password, readers have to unlock the post to see its content.
+// The password is stored as a bcrypt hash, like user passwords. Unlock tokens issued for a
+// previous password stop working.
+func (m PostModel) SetPassword(ctx context.Context, postID int, plaintext string) error {
+	var pw password
+	err := pw.Set(plaintext)
+	if err != nil {
+		return err
+	}
 
-	_, err := m.DB.Exec(ctx, query, postID)
+	query := `
+		UPDATE blog_posts SET password_hash = $1, password_version = password_version + 1, version = version + 1
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := m.DB.Exec(ctx, query, pw.hash, postID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// RemovePassword makes the content of a post readable without a password again.
+func (m PostModel) RemovePassword(ctx context.Context, postID int) error {
+	query := `
+		UPDATE blog_posts SET password_hash = NULL, password_version = password_version + 1, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := m.DB.Exec(ctx, query, postID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// MatchPassword checks plaintext against the password of a protected post.
+// Returns ErrRecordNotFound if the post doesn't exist or isn't protected.
+func (m PostModel) MatchPassword(ctx context.Context, postID int, plaintext string) (bool, error) {
+	query := `SELECT password_hash FROM blog_posts WHERE id = $1 AND password_hash IS NOT NULL AND deleted_at IS NULL`
+
+	var pw password
+	err := m.DB.QueryRow(ctx, query, postID).Scan(&pw.hash)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return ErrEditConflict
+			return false, ErrRecordNotFound
 		default:
-			return err
+			return false, err
 		}
 	}
+
+	return pw.Match(plaintext)
+}
+
+// Publish makes a post visible to everyone. See SetVisibility.
+func (m PostModel) Publish(ctx context.Context, postID int) error {
+	return m.SetVisibility(ctx, postID, VisibilityPublished)
+}
+
+// SetVisibility moves a post to the given visibility state.
+// The first time a post goes live its publish time is recorded in `published_at`,
+// taking a post down and putting it back up keeps the original date. A pending
+// schedule is cancelled unless the post stays a draft.
+// Returns ErrRecordNotFound if the post doesn't exist or is in the trash.
+func (m PostModel) SetVisibility(ctx context.Context, postID int, visibility string) error {
+	query := `UPDATE blog_posts 
+          SET visibility = $1,
+		published_at = CASE WHEN $1 IN ('published', 'unlisted') THEN COALESCE(published_at, NOW()) ELSE published_at END,
+		publish_at = CASE WHEN $1 = 'draft' THEN publish_at END,
+		version = version + 1
+          WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := m.DB.Exec(ctx, query, visibility, postID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
 	return nil
 }
 
-// Delete deletes the specific blog post.
+// PublishDue publishes every scheduled draft whose publish_at time has passed.
+// The scheduled time becomes the post's published_at. Returns the IDs of the published posts.
+func (m PostModel) PublishDue(ctx context.Context) ([]int, error) {
+	query := `UPDATE blog_posts
+          SET visibility = 'published',
+		published_at = publish_at,
+		publish_at = NULL,
+		version = version + 1
+          WHERE visibility = 'draft'
+		AND deleted_at IS NULL
+		AND publish_at IS NOT NULL
+		AND publish_at <= NOW()
+          RETURNING id`
+
+	rows, err := m.DB.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postIDs []int
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return postIDs, nil
+}
+
+// Delete moves the specific blog post to the trash. Trashed posts are hidden
+// everywhere until they are restored, a pending schedule is cancelled.
 func (m PostModel) Delete(ctx context.Context, postID int) error {
-	query := `DELETE FROM blog_posts WHERE id = $1`
+	query := `UPDATE blog_posts
+          SET deleted_at = NOW(),
+		publish_at = NULL
+          WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := m.DB.Exec(ctx, query, postID)
 	if err != nil {
@@ -319,7 +659,142 @@ func (m PostModel) Delete(ctx context.Context, postID int) error {
 	return nil
 }
 
-// GetAll retrieves a paginated list of all blog posts with their tags.
+// Restore takes the specific blog post out of the trash.
+func (m PostModel) Restore(ctx context.Context, postID int) error {
+	query := `UPDATE blog_posts SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := m.DB.Exec(ctx, query, postID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes a trashed blog post along with its tags, revisions and old slugs.
+func (m PostModel) Purge(ctx context.Context, postID int) error {
+	query := `DELETE FROM blog_posts WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := m.DB.Exec(ctx, query, postID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountTrash returns the number of posts that were moved to the trash before the given time.
+func (m PostModel) CountTrash(ctx context.Context, before time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM blog_posts WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	var count int
+	err := m.DB.QueryRow(ctx, query, before).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// EmptyTrash permanently deletes every post that was moved to the trash before the given time.
+// Returns the number of deleted posts.
+func (m PostModel) EmptyTrash(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM blog_posts WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := m.DB.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// GetTrash retrieves a paginated list of trashed posts, most recently deleted first.
+// Content is left out, the trash is only meant for picking posts to restore or purge.
+func (m PostModel) GetTrash(ctx context.Context, filter Filter) ([]*Post, int, error) {
+	query := fmt.Sprintf(`
+SELECT
+    count(*) OVER(),
+    bp.id,
+    u.name AS author,
+    %s,
+    bp.title,
+    bp.description,
+    bp.slug,
+    bp.visibility = 'published' AS is_published,
+    bp.visibility,
+    bp.published_at,
+    bp.created_at,
+    bp.updated_at,
+    bp.deleted_at,
+    COALESCE(ARRAY_AGG(t.name ORDER BY t.name), '{}') AS tags
+FROM
+    blog_posts bp
+JOIN
+    users u ON bp.user_id = u.id
+LEFT JOIN
+    blog_tag bt ON bp.id = bt.blog_id
+LEFT JOIN
+    tags t ON t.id = bt.tag_id
+WHERE
+    bp.deleted_at IS NOT NULL
+GROUP BY
+    bp.id, u.name
+ORDER BY
+    bp.deleted_at DESC, bp.id ASC
+LIMIT $1 OFFSET $2;
+`, authorsColumn)
+
+	rows, err := m.DB.Query(ctx, query, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var posts []*Post
+	var totalCount int
+
+	for rows.Next() {
+		var p Post
+		err := rows.Scan(
+			&totalCount,
+			&p.ID,
+			&p.Author,
+			&p.Authors,
+			&p.Title,
+			&p.Description,
+			&p.Slug,
+			&p.IsPublished,
+			&p.Visibility,
+			&p.PublishedAt,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&p.DeletedAt,
+			&p.Tags,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		p.Status = p.Visibility
+		posts = append(posts, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, totalCount, nil
+}
+
+// GetAll retrieves a paginated list of all blog posts with their tags. Posts without a
+// value in the order column, like drafts that were never published, come last, and ties
+// are broken by ID so pages don't overlap.
 func (m PostModel) GetAll(ctx context.Context, filter Filter) ([]*Post, int, error) {
 	query := fmt.Sprintf(`
 SELECT
@@ -327,13 +802,23 @@ SELECT
     bp.id,
     bp.user_id,
     u.name AS author,
+    %[3]s,
     bp.title,
     bp.description,
     bp.content,
     bp.slug,
-    bp.is_published,
+    bp.visibility = 'published' AS is_published,
+    bp.visibility,
+    bp.password_hash IS NOT NULL AS is_protected,
+    CASE
+        WHEN bp.visibility = 'draft' AND bp.publish_at IS NOT NULL THEN 'scheduled'
+        ELSE bp.visibility
+    END AS status,
+    bp.publish_at,
+    bp.published_at,
     bp.created_at,
     bp.updated_at,
+    bp.version,
     COALESCE(ARRAY_AGG(t.name ORDER BY t.name), '{}') AS tags
 FROM
     blog_posts bp
@@ -350,15 +835,21 @@ WHERE
         JOIN tags t2 ON t2.id = bt2.tag_id
         WHERE t2.name = $3
     ))
-    AND bp.is_published = $4
+    AND (bp.visibility = 'published') = $4
+    AND ($6 = 0 OR bp.id IN (SELECT post_id FROM post_authors WHERE user_id = $6))
+    AND bp.deleted_at IS NULL
+    AND ($5 = '' OR CASE
+        WHEN bp.visibility = 'draft' AND bp.publish_at IS NOT NULL THEN 'scheduled'
+        ELSE bp.visibility
+    END = $5)
 GROUP BY
     bp.id, u.name
 ORDER BY
-    bp.%s %s, bp.id ASC
+    bp.%[1]s %[2]s NULLS LAST, bp.id %[2]s
 LIMIT $1 OFFSET $2;
-`, filter.OrderBy, filter.Sort)
+`, filter.OrderBy, filter.Sort, authorsColumn)
 
-	rows, err := m.DB.Query(ctx, query, filter.Limit, filter.Offset, filter.Tag, filter.IsPublished)
+	rows, err := m.DB.Query(ctx, query, filter.Limit, filter.Offset, filter.Tag, filter.IsPublished, filter.Status, filter.AuthorID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -374,13 +865,20 @@ LIMIT $1 OFFSET $2;
 			&p.ID,
 			&p.UserID,
 			&p.Author,
+			&p.Authors,
 			&p.Title,
 			&p.Description,
 			&p.Content,
 			&p.Slug,
 			&p.IsPublished,
+			&p.Visibility,
+			&p.IsProtected,
+			&p.Status,
+			&p.PublishAt,
+			&p.PublishedAt,
 			&p.CreatedAt,
 			&p.UpdatedAt,
+			&p.Version,
 			&p.Tags,
 		)
 		if err != nil {
@@ -396,13 +894,14 @@ LIMIT $1 OFFSET $2;
 	return posts, totalCount, nil
 }
 
-// YearlyStatsList retrieves a list of years along with the number of posts created in each year.
-// It queries the `posts` table, groups by year extracted from the `created_at` column,
+// YearlyStatsList retrieves a list of years along with the number of posts published in each year.
+// It queries the `posts` table, groups by year extracted from the `published_at` column,
 // and returns the results in descending year order.
 func (m PostModel) YearlyStatsList(ctx context.Context) ([]YearlyStats, error) {
 	query := `
-		SELECT EXTRACT(YEAR FROM created_at)::INT AS year, COUNT(*) AS count
+		SELECT EXTRACT(YEAR FROM published_at)::INT AS year, COUNT(*) AS count
 		FROM blog_posts
+		WHERE visibility = 'published' AND deleted_at IS NULL
 		GROUP BY year
 		ORDER BY year DESC;
 	`
@@ -430,15 +929,16 @@ func (m PostModel) YearlyStatsList(ctx context.Context) ([]YearlyStats, error) {
 	return stats, nil
 }
 
-// GetByYear returns all blog posts created in the given year.
-// It filters the posts using the `created_at` timestamp column.
+// GetByYear returns all blog posts published in the given year.
+// It filters the posts using the `published_at` timestamp column.
 func (m PostModel) GetByYear(ctx context.Context, year int) ([]Post, error) {
 	query := `
-	SELECT id, title, slug, created_at
+	SELECT id, title, slug, published_at
 	FROM blog_posts
-	WHERE EXTRACT(YEAR FROM created_at)::INT = $1
-	  AND is_published = true
-	ORDER BY created_at DESC;
+	WHERE EXTRACT(YEAR FROM published_at)::INT = $1
+	  AND visibility = 'published'
+	  AND deleted_at IS NULL
+	ORDER BY published_at DESC;
 `
 
 	rows, err := m.DB.Query(ctx, query, year)
@@ -451,7 +951,7 @@ func (m PostModel) GetByYear(ctx context.Context, year int) ([]Post, error) {
 
 	for rows.Next() {
 		var p Post
-		if err := rows.Scan(&p.ID, &p.Title, &p.Slug, &p.CreatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.Title, &p.Slug, &p.PublishedAt); err != nil {
 			return nil, err
 		}
 		posts = append(posts, p)
@@ -464,14 +964,16 @@ func (m PostModel) GetByYear(ctx context.Context, year int) ([]Post, error) {
 	return posts, nil
 }
 
-// PreviousID returns the ID of the most recent published post with an ID less than currentID.
+// PreviousID returns the ID of the published post that went live right before currentID.
 // If no such post exists, it returns 0 and nil error.
 func (m PostModel) PreviousID(ctx context.Context, currentID int) (int, error) {
 	const query = `
 		SELECT id 
 		FROM blog_posts
-		WHERE id < $1 AND is_published = true
-		ORDER BY id DESC
+		WHERE visibility = 'published'
+		  AND deleted_at IS NULL
+		  AND (published_at, id) < (SELECT published_at, id FROM blog_posts WHERE id = $1)
+		ORDER BY published_at DESC, id DESC
 		LIMIT 1;
 	`
 
@@ -489,14 +991,16 @@ func (m PostModel) PreviousID(ctx context.Context, currentID int) (int, error) {
 	return postID, nil
 }
 
-// NextID returns the ID of the next published post with an ID greater than currentID.
+// NextID returns the ID of the published post that went live right after currentID.
 // If no such post exists, it returns 0 and nil error.
 func (m PostModel) NextID(ctx context.Context, currentID int) (int, error) {
 	const query = `
 		SELECT id 
 		FROM blog_posts
-		WHERE id > $1 AND is_published = true
-		ORDER BY id ASC
+		WHERE visibility = 'published'
+		  AND deleted_at IS NULL
+		  AND (published_at, id) > (SELECT published_at, id FROM blog_posts WHERE id = $1)
+		ORDER BY published_at ASC, id ASC
 		LIMIT 1;
 	`
 
@@ -527,7 +1031,7 @@ func (m PostModel) UpdateViews(ctx context.Context, postID int) error {
 
 // GetTop10Posts fetches the top 10 blog posts by views.
 func (m PostModel) GetTop10Posts(ctx context.Context) ([]TopPost, error) {
-	query := `SELECT id, title, slug FROM blog_posts WHERE is_published = true ORDER BY views DESC LIMIT 10`
+	query := `SELECT id, title, slug FROM blog_posts WHERE visibility = 'published' AND deleted_at IS NULL ORDER BY views DESC LIMIT 10`
 
 	rows, err := m.DB.Query(ctx, query)
 	if err != nil {
@@ -553,6 +1057,8 @@ func (m PostModel) GetTop10Posts(ctx context.Context) ([]TopPost, error) {
 }
 
 // Search searches published posts using PostgreSQL Full-Text Search.
+// The content of password protected posts isn't searched, only their title and description.
+// The search expression has to match blog_posts_search_idx, or the index goes unused.
 func (m PostModel) Search(ctx context.Context, queryStr string, limit int) ([]SearchResult, error) {
 	sqlQuery := `
 		SELECT
@@ -564,7 +1070,7 @@ func (m PostModel) Search(ctx context.Context, queryStr string, limit int) ([]Se
 			ts_rank(
 				setweight(to_tsvector('simple', bp.title), 'A') ||
 				setweight(to_tsvector('simple', COALESCE(bp.description, '')), 'B') ||
-				setweight(to_tsvector('simple', bp.content), 'C'),
+				setweight(to_tsvector('simple', CASE WHEN bp.password_hash IS NULL THEN bp.content ELSE '' END), 'C'),
 				websearch_to_tsquery('simple', $1)
 			) AS rank
 		FROM
@@ -574,16 +1080,17 @@ func (m PostModel) Search(ctx context.Context, queryStr string, limit int) ([]Se
 		LEFT JOIN
 			tags t ON t.id = bt.tag_id
 		WHERE
-			bp.is_published = true
+			bp.visibility = 'published'
+			AND bp.deleted_at IS NULL
 			AND (
 				setweight(to_tsvector('simple', bp.title), 'A') ||
 				setweight(to_tsvector('simple', COALESCE(bp.description, '')), 'B') ||
-				setweight(to_tsvector('simple', bp.content), 'C')
+				setweight(to_tsvector('simple', CASE WHEN bp.password_hash IS NULL THEN bp.content ELSE '' END), 'C')
 			) @@ websearch_to_tsquery('simple', $1)
 		GROUP BY
 			bp.id
 		ORDER BY
-			rank DESC, bp.created_at DESC
+			rank DESC, bp.published_at DESC
 		LIMIT $2;
 	`
 
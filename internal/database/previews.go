@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PreviewModel handles database operations for draft preview links.
+type PreviewModel struct {
+	DB Pool // Database connection pool
+}
+
+// Preview is a revocable link that lets anyone holding its token read a post before it's published.
+// Only the hash of the token is stored, the token itself is shown once when the preview is created.
+type Preview struct {
+	ID        int       `json:"id"`         // Unique identifier for the preview
+	PostID    int       `json:"post_id"`    // Post the preview gives access to
+	Author    string    `json:"author"`     // Name of the user who created the preview
+	ExpiresAt time.Time `json:"expires_at"` // When the preview link stops working
+	CreatedAt time.Time `json:"created_at"` // When the preview was created
+}
+
+// Create stores a new preview for a post and returns it.
+func (m PreviewModel) Create(ctx context.Context, postID int, userID int64, tokenHash []byte, expiresAt time.Time) (*Preview, error) {
+	query := `INSERT INTO post_previews(post_id, user_id, token_hash, expires_at)
+		  VALUES($1, $2, $3, $4)
+		  RETURNING id, post_id, expires_at, created_at`
+
+	var p Preview
+	err := m.DB.QueryRow(ctx, query, postID, userID, tokenHash, expiresAt).Scan(
+		&p.ID,
+		&p.PostID,
+		&p.ExpiresAt,
+		&p.CreatedAt,
+	)
+	if err != nil {
+		if pgErrorCode(err) == pgForeignKeyViolation {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// GetAll retrieves the previews of a post that haven't expired yet, newest first.
+func (m PreviewModel) GetAll(ctx context.Context, postID int) ([]*Preview, error) {
+	query := `
+		SELECT pp.id, pp.post_id, u.name, pp.expires_at, pp.created_at
+		FROM post_previews pp
+		JOIN users u ON pp.user_id = u.id
+		WHERE pp.post_id = $1 AND pp.expires_at > NOW()
+		ORDER BY pp.id DESC`
+
+	rows, err := m.DB.Query(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var previews []*Preview
+	for rows.Next() {
+		var p Preview
+		err := rows.Scan(&p.ID, &p.PostID, &p.Author, &p.ExpiresAt, &p.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return previews, nil
+}
+
+// GetPostID returns the ID of the post a preview token gives access to.
+// Returns ErrRecordNotFound if the token is unknown, revoked or expired, or the post
+// has been published since: from then on its public URL is the one to share.
+func (m PreviewModel) GetPostID(ctx context.Context, tokenHash []byte) (int, error) {
+	query := `
+		SELECT pp.post_id
+		FROM post_previews pp
+		JOIN blog_posts bp ON bp.id = pp.post_id
+		WHERE pp.token_hash = $1 AND pp.expires_at > NOW() AND bp.visibility <> 'published'`
+
+	var postID int
+	err := m.DB.QueryRow(ctx, query, tokenHash).Scan(&postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return postID, nil
+}
+
+// Delete revokes a preview of a post.
+func (m PreviewModel) Delete(ctx context.Context, postID, previewID int) error {
+	query := `DELETE FROM post_previews WHERE id = $1 AND post_id = $2`
+
+	result, err := m.DB.Exec(ctx, query, previewID, postID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// RefreshToken is a refresh token of a session. Only the hash of the token ID is stored.
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	SessionID int64      `json:"session_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"` // When the token was exchanged for a new one
+}
+
+// RefreshTokenModel handles database operations for refresh tokens.
+type RefreshTokenModel struct {
+	DB Pool // Database connection pool
+}
+
+// Rotate exchanges the refresh token with hash oldHash for a new one in the same session,
+// filling in next, and extends the session until next expires. Returns ErrRecordNotFound
+// if the token is unknown or expired, or its session was revoked. A token that was
+// already rotated is being reused, most likely because it leaked: the whole session is
+// revoked and ErrRefreshTokenReused returned.
+func (m RefreshTokenModel) Rotate(ctx context.Context, oldHash, newHash []byte, next *RefreshToken) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the token so two refreshes racing each other can't both rotate it.
+	var (
+		tokenID   int64
+		expiresAt time.Time
+		rotatedAt *time.Time
+		revokedAt *time.Time
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT rt.id, rt.user_id, rt.session_id, rt.expires_at, rt.rotated_at, s.revoked_at
+		FROM refresh_tokens rt
+		INNER JOIN sessions s ON s.id = rt.session_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, s`, oldHash).Scan(&tokenID, &next.UserID, &next.SessionID, &expiresAt, &rotatedAt, &revokedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	switch {
+	case revokedAt != nil || !expiresAt.After(time.Now()):
+		return ErrRecordNotFound
+	case rotatedAt != nil:
+		_, err = tx.Exec(ctx, `UPDATE sessions SET revoked_at = NOW() WHERE id = $1`, next.SessionID)
+		if err != nil {
+			return err
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return err
+		}
+		return ErrRefreshTokenReused
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE refresh_tokens SET rotated_at = NOW() WHERE id = $1`, tokenID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO refresh_tokens(user_id, session_id, token_hash, expires_at)
+		VALUES($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		next.UserID, next.SessionID, newHash, next.ExpiresAt).Scan(&next.ID, &next.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE sessions SET expires_at = $1, last_seen_at = NOW()
+		WHERE id = $2`, next.ExpiresAt, next.SessionID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
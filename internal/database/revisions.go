@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RevisionModel handles database operations for post revisions.
+type RevisionModel struct {
+	DB Pool // Database connection pool
+}
+
+// Revision is an immutable snapshot of a blog post taken when the post is updated.
+type Revision struct {
+	ID          int       `json:"id"`                    // Unique identifier for the revision
+	PostID      int       `json:"post_id"`               // Post the snapshot belongs to
+	Author      string    `json:"author"`                // Name of the user who saved this version
+	Title       string    `json:"title"`                 // Title at the time of the snapshot
+	Description *string   `json:"description,omitempty"` // Description at the time of the snapshot
+	Content     string    `json:"content,omitempty"`     // Content at the time of the snapshot
+	Slug        string    `json:"slug"`                  // Slug at the time of the snapshot
+	Tags        []string  `json:"tags"`                  // Tags at the time of the snapshot
+	CreatedAt   time.Time `json:"created_at"`            // When this version was saved
+}
+
+// GetAll retrieves all revisions of a post, newest first. Content is omitted to keep the list small.
+func (m RevisionModel) GetAll(ctx context.Context, postID int) ([]*Revision, error) {
+	query := `
+		SELECT
+			pr.id,
+			pr.post_id,
+			COALESCE(u.name, '') AS author,
+			pr.title,
+			pr.description,
+			pr.slug,
+			pr.tags,
+			pr.created_at
+		FROM
+			post_revisions pr
+		LEFT JOIN
+			users u ON pr.user_id = u.id
+		WHERE
+			pr.post_id = $1
+		ORDER BY
+			pr.id DESC
+	`
+
+	rows, err := m.DB.Query(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*Revision
+	for rows.Next() {
+		var r Revision
+		err := rows.Scan(
+			&r.ID,
+			&r.PostID,
+			&r.Author,
+			&r.Title,
+			&r.Description,
+			&r.Slug,
+			&r.Tags,
+			&r.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Get retrieves a single revision of a post including its full content.
+func (m RevisionModel) Get(ctx context.Context, postID, revisionID int) (*Revision, error) {
+	query := `
+		SELECT
+			pr.id,
+			pr.post_id,
+			COALESCE(u.name, '') AS author,
+			pr.title,
+			pr.description,
+			pr.content,
+			pr.slug,
+			pr.tags,
+			pr.created_at
+		FROM
+			post_revisions pr
+		LEFT JOIN
+			users u ON pr.user_id = u.id
+		WHERE
+			pr.post_id = $1 AND pr.id = $2
+	`
+
+	var r Revision
+	err := m.DB.QueryRow(ctx, query, postID, revisionID).Scan(
+		&r.ID,
+		&r.PostID,
+		&r.Author,
+		&r.Title,
+		&r.Description,
+		&r.Content,
+		&r.Slug,
+		&r.Tags,
+		&r.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+// snapshotPost stores the current state of a post as a new revision authored by userID.
+// It runs inside the caller's transaction so the snapshot matches the saved post.
+func snapshotPost(ctx context.Context, tx pgx.Tx, postID int, userID int64) error {
+	query := `
+		INSERT INTO post_revisions(post_id, user_id, title, description, content, slug, tags)
+		SELECT
+			bp.id,
+			$2,
+			bp.title,
+			bp.description,
+			bp.content,
+			bp.slug,
+			COALESCE((
+				SELECT ARRAY_AGG(t.name ORDER BY t.name)
+				FROM blog_tag bt
+				JOIN tags t ON t.id = bt.tag_id
+				WHERE bt.blog_id = bp.id
+			), '{}')
+		FROM
+			blog_posts bp
+		WHERE
+			bp.id = $1
+	`
+
+	_, err := tx.Exec(ctx, query, postID, userID)
+	return err
+}
+
+// snapshotOriginal stores the pre-history state of a post, attributed to its owner, the first
+// time the post is updated. Later updates already have their previous state recorded.
+func snapshotOriginal(ctx context.Context, tx pgx.Tx, postID int) error {
+	query := `
+		INSERT INTO post_revisions(post_id, user_id, title, description, content, slug, tags, created_at)
+		SELECT
+			bp.id,
+			bp.user_id,
+			bp.title,
+			bp.description,
+			bp.content,
+			bp.slug,
+			COALESCE((
+				SELECT ARRAY_AGG(t.name ORDER BY t.name)
+				FROM blog_tag bt
+				JOIN tags t ON t.id = bt.tag_id
+				WHERE bt.blog_id = bp.id
+			), '{}'),
+			bp.updated_at
+		FROM
+			blog_posts bp
+		WHERE
+			bp.id = $1
+			AND NOT EXISTS(SELECT 1 FROM post_revisions WHERE post_id = $1)
+	`
+
+	_, err := tx.Exec(ctx, query, postID)
+	return err
+}
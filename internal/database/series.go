@@ -0,0 +1,319 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// SeriesModel handles database operations for post series.
+type SeriesModel struct {
+	DB Pool // Database connection pool
+}
+
+// Series represents an ordered collection of posts, e.g. a multi-part tutorial.
+type Series struct {
+	ID          int          `json:"id"`                    // Unique identifier for the series
+	Title       string       `json:"title"`                 // Title of the series
+	Slug        string       `json:"slug"`                  // Slug of the series title
+	Description *string      `json:"description,omitempty"` // Short summary of the series
+	PostCount   int          `json:"post_count"`            // Number of posts in the series
+	Parts       []SeriesPart `json:"parts,omitempty"`       // Posts of the series in reading order
+	CreatedAt   time.Time    `json:"created_at"`            // When the series was created
+	UpdatedAt   time.Time    `json:"updated_at"`            // When the series was last updated
+}
+
+// SeriesPart is a post as listed inside a series.
+type SeriesPart struct {
+	ID          int        `json:"id"`                     // Post ID
+	Title       string     `json:"title"`                  // Post title
+	Slug        string     `json:"slug"`                   // Post slug
+	Position    int        `json:"position"`               // Position of the post in the series, starting at 1
+	IsPublished bool       `json:"is_published"`           // Whether the post is live
+	PublishedAt *time.Time `json:"published_at,omitempty"` // When the post went live
+}
+
+// PostSeries describes the series a post belongs to, as shown next to the post.
+type PostSeries struct {
+	ID       int          `json:"id"`       // Series ID
+	Title    string       `json:"title"`    // Series title
+	Slug     string       `json:"slug"`     // Series slug
+	Position int          `json:"position"` // Position of the post among the published parts
+	Total    int          `json:"total"`    // Number of published parts
+	Parts    []SeriesPart `json:"parts"`    // Published parts in reading order
+}
+
+// Create inserts a new series and returns its ID.
+func (m SeriesModel) Create(ctx context.Context, s *Series) (int, error) {
+	query := `INSERT INTO series(title, slug, description)
+		  VALUES($1, $2, $3)
+		  RETURNING id`
+
+	var seriesID int
+	err := m.DB.QueryRow(ctx, query, s.Title, s.Slug, s.Description).Scan(&seriesID)
+	if err != nil {
+		if pgErrorCode(err) == pgUniqueViolation {
+			return 0, ErrDuplicateSlug
+		}
+		return 0, err
+	}
+
+	return seriesID, nil
+}
+
+// Get retrieves a series by its ID with all of its posts, including drafts.
+func (m SeriesModel) Get(ctx context.Context, seriesID int) (*Series, error) {
+	query := `SELECT id, title, slug, description, created_at, updated_at FROM series WHERE id = $1`
+
+	var s Series
+	err := m.DB.QueryRow(ctx, query, seriesID).Scan(
+		&s.ID,
+		&s.Title,
+		&s.Slug,
+		&s.Description,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	s.Parts, err = m.parts(ctx, s.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	s.PostCount = len(s.Parts)
+
+	return &s, nil
+}
+
+// GetBySlug retrieves a series by its slug with its published posts only.
+func (m SeriesModel) GetBySlug(ctx context.Context, slug string) (*Series, error) {
+	query := `SELECT id, title, slug, description, created_at, updated_at FROM series WHERE slug = $1`
+
+	var s Series
+	err := m.DB.QueryRow(ctx, query, slug).Scan(
+		&s.ID,
+		&s.Title,
+		&s.Slug,
+		&s.Description,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	s.Parts, err = m.parts(ctx, s.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	s.PostCount = len(s.Parts)
+
+	return &s, nil
+}
+
+// GetAll retrieves all series with the number of posts in each.
+func (m SeriesModel) GetAll(ctx context.Context) ([]*Series, error) {
+	query := `
+		SELECT
+			s.id,
+			s.title,
+			s.slug,
+			s.description,
+			COUNT(bp.id) AS post_count,
+			s.created_at,
+			s.updated_at
+		FROM
+			series s
+		LEFT JOIN
+			series_posts sp ON sp.series_id = s.id
+		LEFT JOIN
+			blog_posts bp ON bp.id = sp.post_id AND bp.deleted_at IS NULL
+		GROUP BY
+			s.id
+		ORDER BY
+			s.title
+	`
+
+	rows, err := m.DB.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var series []*Series
+	for rows.Next() {
+		var s Series
+		err := rows.Scan(
+			&s.ID,
+			&s.Title,
+			&s.Slug,
+			&s.Description,
+			&s.PostCount,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// Update updates the title, slug and description of a series.
+func (m SeriesModel) Update(ctx context.Context, s *Series) error {
+	query := `
+		UPDATE series
+		SET title = $1, slug = $2, description = $3
+		WHERE id = $4`
+
+	result, err := m.DB.Exec(ctx, query, s.Title, s.Slug, s.Description, s.ID)
+	if err != nil {
+		if pgErrorCode(err) == pgUniqueViolation {
+			return ErrDuplicateSlug
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete deletes a series. The posts themselves are kept.
+func (m SeriesModel) Delete(ctx context.Context, seriesID int) error {
+	query := `DELETE FROM series WHERE id = $1`
+
+	result, err := m.DB.Exec(ctx, query, seriesID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetPosts replaces the posts of a series with postIDs, in the given order.
+func (m SeriesModel) SetPosts(ctx context.Context, seriesID int, postIDs []int) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM series_posts WHERE series_id = $1`, seriesID)
+	if err != nil {
+		return err
+	}
+
+	for i, postID := range postIDs {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO series_posts(series_id, post_id, position) VALUES($1, $2, $3)`,
+			seriesID, postID, i+1)
+		if err != nil {
+			switch pgErrorCode(err) {
+			case pgUniqueViolation:
+				return ErrPostInAnotherSeries
+			case pgForeignKeyViolation:
+				return ErrRecordNotFound
+			default:
+				return err
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByPost retrieves the series a published post belongs to, along with the published parts.
+// Returns nil and nil error if the post isn't part of a series.
+func (m SeriesModel) GetByPost(ctx context.Context, postID int) (*PostSeries, error) {
+	query := `
+		SELECT s.id, s.title, s.slug
+		FROM series s
+		JOIN series_posts sp ON sp.series_id = s.id
+		WHERE sp.post_id = $1`
+
+	var ps PostSeries
+	err := m.DB.QueryRow(ctx, query, postID).Scan(&ps.ID, &ps.Title, &ps.Slug)
+	if err != nil {
+		// No series is fine, the post simply stands on its own.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ps.Parts, err = m.parts(ctx, ps.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.Total = len(ps.Parts)
+	for _, part := range ps.Parts {
+		if part.ID == postID {
+			ps.Position = part.Position
+		}
+	}
+
+	return &ps, nil
+}
+
+// parts retrieves the posts of a series in reading order, optionally only the published ones.
+func (m SeriesModel) parts(ctx context.Context, seriesID int, publishedOnly bool) ([]SeriesPart, error) {
+	query := `
+		SELECT bp.id, bp.title, bp.slug, sp.position, bp.visibility = 'published', bp.published_at
+		FROM series_posts sp
+		JOIN blog_posts bp ON bp.id = sp.post_id
+		WHERE sp.series_id = $1
+		  AND bp.deleted_at IS NULL
+		  AND ($2 = false OR bp.visibility = 'published')
+		ORDER BY sp.position`
+
+	rows, err := m.DB.Query(ctx, query, seriesID, publishedOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parts []SeriesPart
+	for rows.Next() {
+		var p SeriesPart
+		err := rows.Scan(&p.ID, &p.Title, &p.Slug, &p.Position, &p.IsPublished, &p.PublishedAt)
+		if err != nil {
+			return nil, err
+		}
+		// Readers only see published parts, number them without gaps.
+		if publishedOnly {
+			p.Position = len(parts) + 1
+		}
+		parts = append(parts, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// sessionSeenInterval is how often the last seen time of a session in use is updated,
+// so that authenticated reads don't all turn into writes.
+const sessionSeenInterval = time.Minute
+
+// sessionRetention is how long ended sessions are kept along with their refresh tokens,
+// so a leaked token presented after its session ended is still recognized.
+const sessionRetention = 30 * 24 * time.Hour
+
+// Session is a signed in session of a user, started by a login. Its access tokens carry
+// its ID, its refresh tokens belong to it.
+type Session struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	IP         string    `json:"ip"`         // Address the user signed in from
+	UserAgent  string    `json:"user_agent"` // Browser or client the user signed in with
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"` // When the session ends unless it's refreshed
+	Current    bool      `json:"current"`    // Whether the request listing sessions came from this one
+}
+
+// SessionModel handles database operations for sessions.
+type SessionModel struct {
+	DB Pool // Database connection pool
+}
+
+// Create starts a session along with its first refresh token, stored as tokenHash.
+// The session lasts until session.ExpiresAt. Sessions of the user that ended longer than
+// sessionRetention ago are cleaned up on the way.
+func (m SessionModel) Create(ctx context.Context, session *Session, tokenHash []byte) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM sessions
+		WHERE user_id = $1 AND GREATEST(expires_at, revoked_at) < $2`,
+		session.UserID, time.Now().Add(-sessionRetention))
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO sessions(user_id, ip, user_agent, expires_at)
+		VALUES($1, $2, $3, $4)
+		RETURNING id, created_at, last_seen_at`,
+		session.UserID, session.IP, session.UserAgent, session.ExpiresAt).Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refresh_tokens(user_id, session_id, token_hash, expires_at)
+		VALUES($1, $2, $3, $4)`,
+		session.UserID, session.ID, tokenHash, session.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetAll retrieves the active sessions of a user, most recently used first.
+func (m SessionModel) GetAll(ctx context.Context, userID int64) ([]*Session, error) {
+	rows, err := m.DB.Query(ctx, `
+		SELECT id, user_id, ip, user_agent, created_at, last_seen_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+	for rows.Next() {
+		var s Session
+		err := rows.Scan(&s.ID, &s.UserID, &s.IP, &s.UserAgent, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Touch checks that a session of userID is still active and records that it was just
+// used. Returns ErrRecordNotFound if the session is unknown, revoked or expired.
+func (m SessionModel) Touch(ctx context.Context, sessionID, userID int64) error {
+	var lastSeenAt time.Time
+	err := m.DB.QueryRow(ctx, `
+		SELECT last_seen_at
+		FROM sessions
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`,
+		sessionID, userID).Scan(&lastSeenAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	if time.Since(lastSeenAt) < sessionSeenInterval {
+		return nil
+	}
+
+	_, err = m.DB.Exec(ctx, `UPDATE sessions SET last_seen_at = NOW() WHERE id = $1`, sessionID)
+	return err
+}
+
+// Revoke ends a session of userID. Returns ErrRecordNotFound if the user has no such
+// active session.
+func (m SessionModel) Revoke(ctx context.Context, userID, sessionID int64) error {
+	result, err := m.DB.Exec(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RevokeByToken ends the session the refresh token with the given hash belongs to.
+// Returns ErrRecordNotFound if the token is unknown or its session already ended.
+func (m SessionModel) RevokeByToken(ctx context.Context, tokenHash []byte) error {
+	result, err := m.DB.Exec(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE revoked_at IS NULL
+		AND id = (SELECT session_id FROM refresh_tokens WHERE token_hash = $1)`, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RevokeAll ends every session of a user but exceptID, signing them out everywhere else.
+// Pass zero as exceptID to end them all.
+func (m SessionModel) RevokeAll(ctx context.Context, userID, exceptID int64) error {
+	_, err := m.DB.Exec(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`, userID, exceptID)
+	return err
+}
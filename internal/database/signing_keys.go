@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// SigningKey is a key JWTs are signed with. The private key is PKCS #8 encoded and
+// encrypted, see the keyring package.
+type SigningKey struct {
+	ID         int64      `json:"id"`
+	KID        string     `json:"kid"` // Key ID in the header of the tokens
+	Algorithm  string     `json:"algorithm"`
+	PrivateKey []byte     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil for the key that signs
+}
+
+// SigningKeyModel handles database operations for JWT signing keys.
+type SigningKeyModel struct {
+	DB Pool // Database connection pool
+}
+
+// GetAll retrieves the keys that still verify tokens, newest first. The first one
+// signs unless it expires.
+func (m SigningKeyModel) GetAll(ctx context.Context) ([]*SigningKey, error) {
+	rows, err := m.DB.Query(ctx, `
+		SELECT id, kid, algorithm, private_key, created_at, expires_at
+		FROM signing_keys
+		WHERE expires_at IS NULL OR expires_at > NOW()
+		ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*SigningKey{}
+	for rows.Next() {
+		var k SigningKey
+		err := rows.Scan(&k.ID, &k.KID, &k.Algorithm, &k.PrivateKey, &k.CreatedAt, &k.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &k)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Rotate makes key the one that signs. The key signing until now keeps verifying tokens
+// for the grace period, so they don't stop working all at once. Expired keys are deleted.
+func (m SigningKeyModel) Rotate(ctx context.Context, key *SigningKey, grace time.Duration) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM signing_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE signing_keys SET expires_at = $1 WHERE expires_at IS NULL`, time.Now().Add(grace))
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO signing_keys(kid, algorithm, private_key)
+		VALUES($1, $2, $3)
+		RETURNING id, created_at`,
+		key.KID, key.Algorithm, key.PrivateKey).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
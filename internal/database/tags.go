@@ -4,13 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // TagModel handles database operations for tags.
 type TagModel struct {
-	DB *pgxpool.Pool // Database connection pool
+	DB Pool // Database connection pool
 }
 
 // Tag represents a tag in the database.
@@ -65,24 +63,32 @@ func (m TagModel) Create(ctx context.Context, tagName string) (int, error) {
 	return tagID, nil
 }
 
-// GetAll retrieves all tags from the database.
-func (m TagModel) GetAll(ctx context.Context) ([]*Tag, error) {
+// GetAll retrieves all tags with the number of posts using them. Posts in the trash
+// count too, so their tags aren't cleaned up as unused before they're restored.
+// With publishedOnly only published posts outside the trash are counted and tags
+// without any are dropped, so readers don't see tags of drafts or hidden posts.
+func (m TagModel) GetAll(ctx context.Context, publishedOnly bool) ([]*Tag, error) {
 	query := `
 		SELECT 
 			t.id, 
 			t.name,
-			COUNT(bt.blog_id) AS post_count
+			COUNT(bp.id) AS post_count
 		FROM 
 			tags t
 		LEFT JOIN 
 			blog_tag bt ON t.id = bt.tag_id
+		LEFT JOIN
+			blog_posts bp ON bp.id = bt.blog_id
+			AND (NOT $1 OR (bp.visibility = 'published' AND bp.deleted_at IS NULL))
 		GROUP BY 
 			t.id, t.name
+		HAVING
+			NOT $1 OR COUNT(bp.id) > 0
 		ORDER BY 
 			t.name
 	`
 
-	rows, err := m.DB.Query(ctx, query)
+	rows, err := m.DB.Query(ctx, query, publishedOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TwoFactorModel handles database operations for TOTP two-factor authentication
+// and its recovery codes.
+type TwoFactorModel struct {
+	DB Pool // Database connection pool
+}
+
+// TwoFactor is the two-factor state of a user.
+type TwoFactor struct {
+	Secret        string     // Base32 TOTP secret, empty if enrollment never started
+	EnabledAt     *time.Time // When enrollment was confirmed, nil while it's pending or off
+	LastStep      int64      // Last TOTP time step a code was accepted for
+	RecoveryCodes int        // Number of unused recovery codes
+}
+
+// Enabled reports whether codes are required to sign in.
+func (tf *TwoFactor) Enabled() bool {
+	return tf.EnabledAt != nil
+}
+
+// Get returns the two-factor state of a user.
+func (m TwoFactorModel) Get(ctx context.Context, userID int64) (*TwoFactor, error) {
+	query := `
+        SELECT COALESCE(u.totp_secret, ''), u.totp_enabled_at, u.totp_last_step,
+               (SELECT count(*) FROM recovery_codes rc WHERE rc.user_id = u.id AND rc.used_at IS NULL)
+        FROM users u
+        WHERE u.id = $1`
+
+	var tf TwoFactor
+	err := m.DB.QueryRow(ctx, query, userID).Scan(&tf.Secret, &tf.EnabledAt, &tf.LastStep, &tf.RecoveryCodes)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &tf, nil
+}
+
+// SetSecret starts enrollment with a new secret, replacing any pending one.
+// Returns ErrTwoFactorEnabled if two-factor auth is already on.
+func (m TwoFactorModel) SetSecret(ctx context.Context, userID int64, secret string) error {
+	query := `
+        UPDATE users
+        SET totp_secret = $2, totp_last_step = 0
+        WHERE id = $1 AND totp_enabled_at IS NULL`
+
+	result, err := m.DB.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// Enable confirms enrollment after the user proved they have the secret with a code
+// for time step step, and stores the hashes of their recovery codes.
+// Returns ErrTwoFactorEnabled if two-factor auth is already on.
+func (m TwoFactorModel) Enable(ctx context.Context, userID, step int64, codeHashes [][]byte) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+        UPDATE users
+        SET totp_enabled_at = NOW(), totp_last_step = $2
+        WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL`, userID, step)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTwoFactorEnabled
+	}
+
+	err = replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Disable turns two-factor auth off and deletes the secret and recovery codes.
+func (m TwoFactorModel) Disable(ctx context.Context, userID int64) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+        UPDATE users
+        SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0
+        WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseStep records that a code for time step step was accepted. It reports false if
+// that step or a later one was already used, i.e. the code is being replayed.
+func (m TwoFactorModel) UseStep(ctx context.Context, userID, step int64) (bool, error) {
+	query := `
+        UPDATE users
+        SET totp_last_step = $2
+        WHERE id = $1 AND totp_last_step < $2`
+
+	result, err := m.DB.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode marks a recovery code as used. It reports false if the code is
+// unknown or was already used.
+func (m TwoFactorModel) UseRecoveryCode(ctx context.Context, userID int64, codeHash []byte) (bool, error) {
+	query := `
+        UPDATE recovery_codes
+        SET used_at = NOW()
+        WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	result, err := m.DB.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// ReplaceRecoveryCodes swaps all recovery codes of a user, used or not, for new ones.
+func (m TwoFactorModel) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes [][]byte) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// replaceRecoveryCodes swaps the recovery codes of a user inside tx.
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int64, codeHashes [][]byte) error {
+	_, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO recovery_codes (user_id, code_hash)
+        SELECT $1, UNNEST($2::bytea[])`, userID, codeHashes)
+	return err
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// UnlockAttemptModel handles database operations for attempts to unlock protected posts.
+type UnlockAttemptModel struct {
+	DB Pool // Database connection pool
+}
+
+// UnlockAttempt counts the attempts to unlock a protected post.
+type UnlockAttempt struct {
+	Attempts     int        // Attempts from the address since its last ban or unlock
+	BannedUntil  *time.Time // Set while the address may not try again
+	PostAttempts int        // Attempts on the post from every address since the given time, this one excluded
+}
+
+// Count records an attempt to unlock postID from ip, before its password is checked so
+// concurrent guesses can't slip past the limit. An address that made more than
+// maxAttempts gets banned until bannedUntil, once the ban passed it starts over.
+// PostAttempts sums the attempts on the post made since the given time.
+func (m UnlockAttemptModel) Count(ctx context.Context, postID int, ip string, maxAttempts int, bannedUntil, since time.Time) (*UnlockAttempt, error) {
+	query := `
+		WITH attempt AS (
+			INSERT INTO post_unlock_attempts AS pua (post_id, ip)
+			VALUES ($1, $2)
+			ON CONFLICT (post_id, ip) DO UPDATE
+			SET attempts = CASE WHEN pua.banned_until <= NOW() THEN 1 ELSE pua.attempts + 1 END,
+			    banned_until = CASE
+			        WHEN pua.banned_until > NOW() THEN pua.banned_until
+			        WHEN pua.banned_until IS NULL AND pua.attempts + 1 > $3 THEN $4
+			    END,
+			    last_attempt = NOW()
+			RETURNING attempts, banned_until
+		)
+		SELECT a.attempts, a.banned_until, (
+			SELECT COALESCE(SUM(attempts), 0)
+			FROM post_unlock_attempts
+			WHERE post_id = $1 AND last_attempt > $5
+		)
+		FROM attempt a`
+
+	var attempt UnlockAttempt
+	err := m.DB.QueryRow(ctx, query, postID, ip, maxAttempts, bannedUntil, since).Scan(
+		&attempt.Attempts,
+		&attempt.BannedUntil,
+		&attempt.PostAttempts,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// Reset forgets the attempts of ip on postID after it unlocked the post.
+func (m UnlockAttemptModel) Reset(ctx context.Context, postID int, ip string) error {
+	query := `DELETE FROM post_unlock_attempts WHERE post_id = $1 AND ip = $2`
+
+	_, err := m.DB.Exec(ctx, query, postID, ip)
+	return err
+}
@@ -4,16 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/gosimple/slug"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // UserModel type.
 type UserModel struct {
-	DB *pgxpool.Pool
+	DB Pool
 }
 
 // User struct to represent an individual user. Importantly, notice how we are
@@ -21,12 +22,60 @@ type UserModel struct {
 // any output when we encode it to JSON. Also notice that the Password field uses the
 // custom password type defined below.
 type User struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  password  `json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email"`
+	Password  password `json:"-"`
+	Role      string   `json:"role"`
+	Handle    string   `json:"handle"`
+	Bio       string   `json:"bio"`
+	AvatarURL string   `json:"avatar_url"`
+	Links     []Link   `json:"links"`
+	// DeactivatedAt is set while the user is locked out, their posts stay untouched.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	// TwoFactorEnabled is set when signing in requires a TOTP or recovery code.
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Link is a social or personal link shown on an author profile.
+type Link struct {
+	Name string `json:"name"` // Label of the link, e.g. GitHub
+	URL  string `json:"url"`  // Where the link points to
+}
+
+// Profile is the public part of a user, shown on author pages.
+type Profile struct {
+	ID        int64  `json:"id"`
+	Handle    string `json:"handle"`
+	Name      string `json:"name"`
+	Bio       string `json:"bio"`
+	AvatarURL string `json:"avatar_url"`
+	Links     []Link `json:"links"`
+}
+
+// User roles, from most to least privileged.
+const (
+	RoleAdmin       = "admin"       // Manages everything, including users
+	RoleEditor      = "editor"      // Edits, publishes and deletes any post
+	RoleAuthor      = "author"      // Writes and edits their own posts, can't publish
+	RoleContributor = "contributor" // Writes drafts and edits them until they're published
+)
+
+// ValidRole reports whether role is a known user role.
+func ValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleEditor, RoleAuthor, RoleContributor:
+		return true
+	}
+	return false
+}
+
+// ValidHandle reports whether handle can be used as is in the URL of an author page:
+// lowercase letters, digits and dashes, at most 64 of them.
+func ValidHandle(handle string) bool {
+	return handle != "" && len(handle) <= 64 && handle == slug.Make(handle)
 }
 
 // LoginAttempt represents a record from the login_attempts table.
@@ -80,18 +129,49 @@ func (p *password) Match(plaintextPassword string) (bool, error) {
 	return true, nil
 }
 
+// maxDerivedHandleLength leaves room in the 64 characters of a handle to append the user ID.
+const maxDerivedHandleLength = 52
+
 // Insert a new record in the database for the user. Note that the id, created_at and
 // fields are all automatically generated by our database, so we use the
 // RETURNING clause to read them into the User struct after the insert.
+//
+// Without a handle one is derived from the name. Like migration 000022 did for existing
+// users, it gets the user ID appended if another user has it already, and becomes
+// "user-<id>" if nothing of the name is URL-safe. A handle that was given has to be free.
 func (m UserModel) Insert(ctx context.Context, user *User) (int64, error) {
 	query := `
-                INSERT INTO users (name, email, password_hash)
-                VALUES ($1, $2, $3)
-                RETURNING id`
+                WITH next AS (SELECT nextval(pg_get_serial_sequence('users', 'id')) AS id)
+                INSERT INTO users (id, name, email, password_hash, role, handle)
+                SELECT next.id, $1, $2, $3, $4, CASE
+                        WHEN $5 = '' THEN 'user-' || next.id
+                        WHEN $6 AND EXISTS (SELECT 1 FROM users WHERE handle = $5) THEN $5 || '-' || next.id
+                        ELSE $5
+                    END
+                FROM next
+                RETURNING id, handle`
+
+	if user.Role == "" {
+		user.Role = RoleAuthor
+	}
+	derived := user.Handle == ""
+	if derived {
+		user.Handle = slug.Make(user.Name)
+		if len(user.Handle) > maxDerivedHandleLength {
+			user.Handle = strings.TrimRight(user.Handle[:maxDerivedHandleLength], "-")
+		}
+	}
+
+	args := []any{user.Name, user.Email, user.Password.hash, user.Role, user.Handle, derived}
 
-	args := []any{user.Name, user.Email, user.Password.hash}
+	err := m.DB.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Handle)
+	err = duplicateUserError(err)
 
-	err := m.DB.QueryRow(ctx, query, args...).Scan(&user.ID)
+	// Another user with the same name may have been added in the meantime, then the
+	// handle is taken by now.
+	if derived && errors.Is(err, ErrDuplicateHandle) {
+		err = duplicateUserError(m.DB.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Handle))
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -104,7 +184,8 @@ func (m UserModel) Insert(ctx context.Context, user *User) (int64, error) {
 // return one record (or none at all, in which case we return a ErrRecordNotFound error).
 func (m UserModel) GetByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
-                SELECT id, name, email, password_hash, created_at, updated_at
+                SELECT id, name, email, password_hash, role, handle, bio, avatar_url, links, deactivated_at,
+                       totp_enabled_at IS NOT NULL, created_at, updated_at
                 FROM users
                 WHERE email = $1`
 
@@ -115,6 +196,13 @@ func (m UserModel) GetByEmail(ctx context.Context, email string) (*User, error)
 		&user.Name,
 		&user.Email,
 		&user.Password.hash,
+		&user.Role,
+		&user.Handle,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.Links,
+		&user.DeactivatedAt,
+		&user.TwoFactorEnabled,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -135,7 +223,8 @@ func (m UserModel) GetByEmail(ctx context.Context, email string) (*User, error)
 // return one record (or none at all, in which case we return a ErrRecordNotFound error).
 func (m UserModel) GetByID(ctx context.Context, userID int64) (*User, error) {
 	query := `
-                SELECT id, name, email, password_hash, created_at, updated_at
+                SELECT id, name, email, password_hash, role, handle, bio, avatar_url, links, deactivated_at,
+                       totp_enabled_at IS NOT NULL, created_at, updated_at
                 FROM users
                 WHERE id = $1`
 
@@ -146,6 +235,13 @@ func (m UserModel) GetByID(ctx context.Context, userID int64) (*User, error) {
 		&user.Name,
 		&user.Email,
 		&user.Password.hash,
+		&user.Role,
+		&user.Handle,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.Links,
+		&user.DeactivatedAt,
+		&user.TwoFactorEnabled,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -161,29 +257,261 @@ func (m UserModel) GetByID(ctx context.Context, userID int64) (*User, error) {
 	return &user, nil
 }
 
-// Update the details for a specific user.
+// Update the details for a specific user: name, email, password and role.
 func (m UserModel) Update(ctx context.Context, user *User) error {
 	query := `
                 UPDATE users
-                SET name = $1, email = $2, password_hash = $3
-                WHERE id = $4`
+                SET name = $1, email = $2, password_hash = $3, role = $4
+                WHERE id = $5`
 
 	args := []any{
 		user.Name,
 		user.Email,
 		user.Password.hash,
+		user.Role,
 		user.ID,
 	}
 
-	_, err := m.DB.Exec(ctx, query, args...)
+	result, err := m.DB.Exec(ctx, query, args...)
+	if err != nil {
+		return duplicateUserError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetAll retrieves a paginated list of users ordered by ID, along with the total count.
+func (m UserModel) GetAll(ctx context.Context, limit, offset int) ([]*User, int, error) {
+	query := `
+        SELECT count(*) OVER(), id, name, email, role, handle, bio, avatar_url, links, deactivated_at,
+               totp_enabled_at IS NOT NULL, created_at, updated_at
+        FROM users
+        ORDER BY id ASC
+        LIMIT $1 OFFSET $2`
+
+	rows, err := m.DB.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	var totalCount int
+
+	for rows.Next() {
+		var user User
+		err := rows.Scan(
+			&totalCount,
+			&user.ID,
+			&user.Name,
+			&user.Email,
+			&user.Role,
+			&user.Handle,
+			&user.Bio,
+			&user.AvatarURL,
+			&user.Links,
+			&user.DeactivatedAt,
+			&user.TwoFactorEnabled,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, totalCount, nil
+}
+
+// SetDeactivated deactivates or reactivates a specific user.
+func (m UserModel) SetDeactivated(ctx context.Context, userID int64, deactivated bool) error {
+	query := `
+        UPDATE users
+        SET deactivated_at = CASE WHEN $2 THEN COALESCE(deactivated_at, NOW()) END
+        WHERE id = $1`
+
+	result, err := m.DB.Exec(ctx, query, userID, deactivated)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete permanently deletes a specific user. Posts the user owns or co-authored are
+// handed over to reassignTo, without one the user must not own any posts, those
+// would be deleted along with the user.
+func (m UserModel) Delete(ctx context.Context, userID, reassignTo int64) error {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if reassignTo == 0 {
+		var owned bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM blog_posts WHERE user_id = $1)`, userID).Scan(&owned)
+		if err != nil {
+			return err
+		}
+		if owned {
+			return ErrUserHasPosts
+		}
+	} else {
+		_, err = tx.Exec(ctx, `UPDATE blog_posts SET user_id = $2 WHERE user_id = $1`, userID, reassignTo)
+		if err != nil {
+			if pgErrorCode(err) == pgForeignKeyViolation {
+				return ErrRecordNotFound
+			}
+			return err
+		}
+
+		// Keep the byline position, unless the new owner is already credited.
+		_, err = tx.Exec(ctx, `
+			UPDATE post_authors pa
+			SET user_id = $2
+			WHERE pa.user_id = $1
+			  AND NOT EXISTS (SELECT 1 FROM post_authors o WHERE o.post_id = pa.post_id AND o.user_id = $2)`,
+			userID, reassignTo)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateProfile updates the public profile of a specific user: name, handle, bio, avatar and links.
+func (m UserModel) UpdateProfile(ctx context.Context, user *User) error {
+	query := `
+        UPDATE users
+        SET name = $1, handle = $2, bio = $3, avatar_url = $4, links = $5, updated_at = NOW()
+        WHERE id = $6
+        RETURNING updated_at`
+
+	if user.Links == nil {
+		user.Links = []Link{}
+	}
+
+	args := []any{user.Name, user.Handle, user.Bio, user.AvatarURL, user.Links, user.ID}
+
+	err := m.DB.QueryRow(ctx, query, args...).Scan(&user.UpdatedAt)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return ErrEditConflict
+			return ErrRecordNotFound
 		default:
-			return err
+			return duplicateUserError(err)
+		}
+	}
+	return nil
+}
+
+// GetProfile retrieves the public profile of the user with the given handle.
+func (m UserModel) GetProfile(ctx context.Context, handle string) (*Profile, error) {
+	query := `
+        SELECT id, handle, name, bio, avatar_url, links
+        FROM users
+        WHERE handle = $1`
+
+	var p Profile
+	err := m.DB.QueryRow(ctx, query, handle).Scan(&p.ID, &p.Handle, &p.Name, &p.Bio, &p.AvatarURL, &p.Links)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
 		}
 	}
+
+	return &p, nil
+}
+
+// GetAuthors retrieves the profiles of all users credited on at least one published post.
+func (m UserModel) GetAuthors(ctx context.Context) ([]*Profile, error) {
+	query := `
+        SELECT u.id, u.handle, u.name, u.bio, u.avatar_url, u.links
+        FROM users u
+        WHERE EXISTS (
+            SELECT 1
+            FROM post_authors pa
+            JOIN blog_posts bp ON bp.id = pa.post_id
+            WHERE pa.user_id = u.id
+              AND bp.visibility = 'published'
+              AND bp.deleted_at IS NULL
+        )
+        ORDER BY u.handle`
+
+	rows, err := m.DB.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*Profile
+	for rows.Next() {
+		var p Profile
+		err := rows.Scan(&p.ID, &p.Handle, &p.Name, &p.Bio, &p.AvatarURL, &p.Links)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// duplicateUserError maps unique violations on users to ErrDuplicateHandle or ErrDuplicateEmail.
+func duplicateUserError(err error) error {
+	if pgErrorCode(err) != pgUniqueViolation {
+		return err
+	}
+	if pgConstraintName(err) == "users_handle_key" {
+		return ErrDuplicateHandle
+	}
+	return ErrDuplicateEmail
+}
+
+// UpdateRole changes the role of a specific user.
+func (m UserModel) UpdateRole(ctx context.Context, userID int64, role string) error {
+	query := `
+        UPDATE users
+        SET role = $1
+        WHERE id = $2`
+
+	result, err := m.DB.Exec(ctx, query, role, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
 	return nil
 }
 
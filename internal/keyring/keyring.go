@@ -0,0 +1,436 @@
+// Package keyring signs and verifies the JWTs of the blog with asymmetric keys that can
+// be rotated.
+//
+// The keys live in the database, their private halves encrypted with a secret from the
+// config so a leaked database dump can't sign tokens. The newest key signs, keys that were rotated out keep
+// verifying tokens until their grace period ends. Every token names the key it was
+// signed with in its "kid" header, and the public keys are published as a JWK set so
+// other services can verify tokens without sharing a secret.
+//
+// Tokens signed with the shared HS256 secrets before the first key was generated keep
+// working until they expire, so generating it signs nobody out.
+package keyring
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// Supported signing algorithms, named as in the "alg" header of a JWT.
+const (
+	EdDSA = "EdDSA" // Ed25519 signatures
+	RS256 = "RS256" // RSASSA-PKCS1-v1_5 with SHA-256
+)
+
+// rsaKeyBits is the size of generated RSA keys.
+const rsaKeyBits = 2048
+
+const (
+	// reloadInterval is how often the keys are reloaded, picking up rotations done by
+	// the CLI or another instance of the server.
+	reloadInterval = time.Minute
+
+	// minReloadInterval limits reloads triggered by tokens signed with an unknown key,
+	// which anyone can send.
+	minReloadInterval = 5 * time.Second
+)
+
+var (
+	// ErrNoKeys is returned when no signing key was generated yet.
+	ErrNoKeys = errors.New("no signing keys")
+
+	// ErrUnknownKey is returned when a token was signed with a key the keyring doesn't
+	// have, or one whose grace period ended.
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+
+	// ErrNoSecret is returned when a key has to be encrypted or decrypted but no secret
+	// was configured.
+	ErrNoSecret = errors.New("no secret to encrypt signing keys with, set jwt.key_secret")
+
+	// ErrLegacyToken is returned for a token signed with HMAC while such tokens may still
+	// be around. The caller verifies it with the shared secret.
+	ErrLegacyToken = errors.New("token signed with a shared secret")
+)
+
+// Key is a signing key.
+type Key struct {
+	ID        string     // RFC 7638 thumbprint of the public key
+	Algorithm string     // EdDSA or RS256
+	CreatedAt time.Time  // When the key was generated
+	ExpiresAt *time.Time // When a rotated out key stops verifying, nil for the key that signs
+	signer    crypto.Signer
+}
+
+// Generate creates a new key for alg.
+func Generate(alg string) (*Key, error) {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	switch alg {
+	case EdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case RS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q, use %s or %s", alg, EdDSA, RS256)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newKey(alg, signer)
+}
+
+// newKey wraps signer, whose type has to match alg.
+func newKey(alg string, signer crypto.Signer) (*Key, error) {
+	switch signer.(type) {
+	case ed25519.PrivateKey:
+		if alg != EdDSA {
+			return nil, fmt.Errorf("an Ed25519 key can't sign %s", alg)
+		}
+	case *rsa.PrivateKey:
+		if alg != RS256 {
+			return nil, fmt.Errorf("an RSA key can't sign %s", alg)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", signer)
+	}
+
+	kid, err := thumbprint(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{ID: kid, Algorithm: alg, signer: signer}, nil
+}
+
+// Record returns k the way it's stored in the database, its private key encrypted with
+// secret.
+func (k *Key) Record(secret string) (*database.SigningKey, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.signer)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key ID is authenticated along with the key, so records can't be swapped.
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, der, []byte(k.ID))
+
+	return &database.SigningKey{KID: k.ID, Algorithm: k.Algorithm, PrivateKey: sealed}, nil
+}
+
+// FromRecord loads a key stored in the database, decrypting it with secret.
+func FromRecord(rec *database.SigningKey, secret string) (*Key, error) {
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rec.PrivateKey) < aead.NonceSize() {
+		return nil, fmt.Errorf("signing key %s: encrypted key too short", rec.KID)
+	}
+	nonce, sealed := rec.PrivateKey[:aead.NonceSize()], rec.PrivateKey[aead.NonceSize():]
+	der, err := aead.Open(nil, nonce, sealed, []byte(rec.KID))
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: can't decrypt it, was jwt.key_secret changed? %w", rec.KID, err)
+	}
+
+	priv, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", rec.KID, err)
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s: unsupported key type %T", rec.KID, priv)
+	}
+
+	k, err := newKey(rec.Algorithm, signer)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", rec.KID, err)
+	}
+	if k.ID != rec.KID {
+		return nil, fmt.Errorf("signing key %s: key ID doesn't match the key", rec.KID)
+	}
+
+	k.CreatedAt, k.ExpiresAt = rec.CreatedAt, rec.ExpiresAt
+	return k, nil
+}
+
+// newAEAD returns AES-256-GCM keyed with a key derived from secret.
+func newAEAD(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, ErrNoSecret
+	}
+
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, "blog signing keys", 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// JWK is the public half of a key as a JSON Web Key (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv,omitempty"` // OKP keys
+	X         string `json:"x,omitempty"`   // OKP keys
+	N         string `json:"n,omitempty"`   // RSA keys
+	E         string `json:"e,omitempty"`   // RSA keys
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key of k.
+func (k *Key) JWK() JWK {
+	jwk := publicJWK(k.signer.Public())
+	jwk.Use = "sig"
+	jwk.Algorithm = k.Algorithm
+	jwk.KeyID = k.ID
+	return jwk
+}
+
+// publicJWK returns the key type and key material of pub, the members that make up its
+// thumbprint.
+func publicJWK(pub crypto.PublicKey) JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := pub.(type) {
+	case ed25519.PublicKey:
+		return JWK{KeyType: "OKP", Curve: "Ed25519", X: b64(pub)}
+	case *rsa.PublicKey:
+		return JWK{KeyType: "RSA", N: b64(pub.N.Bytes()), E: b64(big.NewInt(int64(pub.E)).Bytes())}
+	default:
+		return JWK{}
+	}
+}
+
+// thumbprint computes the RFC 7638 thumbprint of pub: the SHA-256 of its required JWK
+// members in lexicographic order, without whitespace.
+func thumbprint(pub crypto.PublicKey) (string, error) {
+	jwk := publicJWK(pub)
+
+	var canonical string
+	switch jwk.KeyType {
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, jwk.Curve, jwk.KeyType, jwk.X)
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, jwk.E, jwk.KeyType, jwk.N)
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// Loader loads the stored keys that still verify tokens, newest first.
+type Loader func(ctx context.Context) ([]*database.SigningKey, error)
+
+// Keyring holds the signing keys, reloading them now and then.
+type Keyring struct {
+	load        Loader
+	secret      string
+	legacyGrace time.Duration
+	logger      *slog.Logger
+
+	mu       sync.Mutex
+	keys     []*Key // Newest first
+	loaded   bool
+	loadedAt time.Time
+}
+
+// New returns a Keyring whose keys come from load, encrypted with secret. Tokens signed
+// with a shared secret are left to the caller for legacyGrace after the oldest key was
+// created, the longest a token signed before it may live.
+func New(load Loader, secret string, legacyGrace time.Duration, logger *slog.Logger) *Keyring {
+	return &Keyring{load: load, secret: secret, legacyGrace: legacyGrace, logger: logger}
+}
+
+// Sign signs claims with the newest key. Returns ErrNoKeys if there is none.
+func (r *Keyring) Sign(ctx context.Context, claims jwt.MapClaims) (string, error) {
+	keys, err := r.current(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 || keys[0].ExpiresAt != nil {
+		return "", ErrNoKeys
+	}
+
+	k := keys[0]
+	t := jwt.NewWithClaims(jwt.GetSigningMethod(k.Algorithm), claims)
+	t.Header["kid"] = k.ID
+
+	return t.SignedString(k.signer)
+}
+
+// Parse verifies token and returns its claims. The token has to be signed with a key
+// of the keyring whose grace period hasn't ended, using the algorithm of that key.
+// Returns ErrNoKeys if there are no keys at all, and ErrLegacyToken for an HMAC signed
+// token within the legacy grace period.
+//
+// The grace period is counted from the oldest key kept. The first key is only deleted
+// after it was rotated out and its own grace period ended, which lasts as long.
+func (r *Keyring) Parse(ctx context.Context, token string) (jwt.MapClaims, error) {
+	keys, err := r.current(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	if unverified, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{}); err == nil {
+		if _, ok := unverified.Method.(*jwt.SigningMethodHMAC); ok {
+			if time.Since(keys[len(keys)-1].CreatedAt) < r.legacyGrace {
+				return nil, ErrLegacyToken
+			}
+			return nil, ErrUnknownKey
+		}
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, err := r.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		// The key decides the algorithm, never the token.
+		if t.Method.Alg() != k.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+
+		return k.signer.Public(), nil
+	}, jwt.WithValidMethods([]string{EdDSA, RS256}))
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the public keys that verify tokens.
+func (r *Keyring) JWKS(ctx context.Context) (JWKS, error) {
+	keys, err := r.current(ctx, false)
+	if err != nil {
+		return JWKS{}, err
+	}
+
+	set := JWKS{Keys: []JWK{}}
+	now := time.Now()
+	for _, k := range keys {
+		if k.ExpiresAt == nil || k.ExpiresAt.After(now) {
+			set.Keys = append(set.Keys, k.JWK())
+		}
+	}
+
+	return set, nil
+}
+
+// key returns the key with the given ID, reloading the keys once in case it's new.
+func (r *Keyring) key(ctx context.Context, kid string) (*Key, error) {
+	for _, force := range []bool{false, true} {
+		keys, err := r.current(ctx, force)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, k := range keys {
+			if k.ID != kid {
+				continue
+			}
+			if k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()) {
+				return nil, ErrUnknownKey
+			}
+			return k, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// current returns the keys, reloading them if they're stale. force reloads them sooner,
+// though not more often than minReloadInterval. If reloading fails the keys loaded
+// before are kept.
+func (r *Keyring) current(ctx context.Context, force bool) ([]*Key, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	age := time.Since(r.loadedAt)
+	if r.loaded && age < reloadInterval && (!force || age < minReloadInterval) {
+		return r.keys, nil
+	}
+
+	keys, err := r.reload(ctx)
+	if err != nil {
+		if !r.loaded {
+			return nil, err
+		}
+		r.logger.Error("failed to reload signing keys", "error", err)
+		r.loadedAt = time.Now()
+		return r.keys, nil
+	}
+
+	r.keys, r.loaded, r.loadedAt = keys, true, time.Now()
+	return r.keys, nil
+}
+
+// reload loads and decodes the stored keys.
+func (r *Keyring) reload(ctx context.Context) ([]*Key, error) {
+	recs, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(recs))
+	for _, rec := range recs {
+		k, err := FromRecord(rec, r.secret)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
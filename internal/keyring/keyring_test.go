@@ -0,0 +1,368 @@
+package keyring
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// testSecret encrypts the keys of the tests.
+const testSecret = "key-secret"
+
+// store is an in-memory stand-in for the signing_keys table.
+type store struct {
+	recs  []*database.SigningKey
+	loads int
+}
+
+func (s *store) load(context.Context) ([]*database.SigningKey, error) {
+	s.loads++
+	return s.recs, nil
+}
+
+// rotate makes k the signing key, the way SigningKeyModel.Rotate does.
+func (s *store) rotate(t *testing.T, k *Key, grace time.Duration) {
+	t.Helper()
+	rec, err := k.Record(testSecret)
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	rec.CreatedAt = time.Now()
+	expiresAt := time.Now().Add(grace)
+	for _, old := range s.recs {
+		if old.ExpiresAt == nil {
+			old.ExpiresAt = &expiresAt
+		}
+	}
+	s.recs = append([]*database.SigningKey{rec}, s.recs...)
+}
+
+func newKeyring(s *store) *Keyring {
+	return New(s.load, testSecret, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func mustGenerate(t *testing.T, alg string) *Key {
+	t.Helper()
+	k, err := Generate(alg)
+	if err != nil {
+		t.Fatalf("Generate(%s): %v", alg, err)
+	}
+	return k
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"user_id": 7, "type": "access", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestThumbprint(t *testing.T) {
+	b64 := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	// RFC 7638, section 3.1.
+	n := new(big.Int).SetBytes(b64("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"))
+	rsaKey := &rsa.PublicKey{N: n, E: 65537}
+	got, err := thumbprint(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("RSA thumbprint = %s, want %s", got, want)
+	}
+
+	// RFC 8037, appendix A.3.
+	got, err = thumbprint(ed25519.PublicKey(b64("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"; got != want {
+		t.Errorf("Ed25519 thumbprint = %s, want %s", got, want)
+	}
+}
+
+func TestSignAndParse(t *testing.T) {
+	for _, alg := range []string{EdDSA, RS256} {
+		t.Run(alg, func(t *testing.T) {
+			s := &store{}
+			k := mustGenerate(t, alg)
+			s.rotate(t, k, 0)
+			r := newKeyring(s)
+
+			token, err := r.Sign(context.Background(), claims())
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Header["alg"] != alg || parsed.Header["kid"] != k.ID {
+				t.Errorf("header = %v, want alg %s and kid %s", parsed.Header, alg, k.ID)
+			}
+
+			got, err := r.Parse(context.Background(), token)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got["user_id"] != float64(7) {
+				t.Errorf("user_id = %v, want 7", got["user_id"])
+			}
+		})
+	}
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	for _, alg := range []string{EdDSA, RS256} {
+		k := mustGenerate(t, alg)
+		rec, err := k.Record(testSecret)
+		if err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+
+		loaded, err := FromRecord(rec, testSecret)
+		if err != nil {
+			t.Fatalf("FromRecord: %v", err)
+		}
+		if loaded.ID != k.ID || loaded.Algorithm != alg {
+			t.Errorf("loaded %s key %s, want %s", loaded.Algorithm, loaded.ID, k.ID)
+		}
+
+		rec.KID = "tampered"
+		if _, err := FromRecord(rec, testSecret); err == nil {
+			t.Errorf("%s: FromRecord accepted a key ID that doesn't match the key", alg)
+		}
+
+		rec.KID, rec.Algorithm = k.ID, map[string]string{EdDSA: RS256, RS256: EdDSA}[alg]
+		if _, err := FromRecord(rec, testSecret); err == nil {
+			t.Errorf("%s: FromRecord accepted the wrong algorithm", alg)
+		}
+	}
+
+	if _, err := Generate("HS256"); err == nil {
+		t.Error("Generate accepted HS256")
+	}
+}
+
+func TestRecordEncrypted(t *testing.T) {
+	k := mustGenerate(t, EdDSA)
+	rec, err := k.Record(testSecret)
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if _, err := x509.ParsePKCS8PrivateKey(rec.PrivateKey); err == nil {
+		t.Error("Record stored the private key in the clear")
+	}
+	if _, err := FromRecord(rec, "other-secret"); err == nil {
+		t.Error("FromRecord decrypted the key with the wrong secret")
+	}
+
+	// A key can't be moved to the record of another key.
+	other, err := mustGenerate(t, EdDSA).Record(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.PrivateKey = rec.PrivateKey
+	if _, err := FromRecord(other, testSecret); err == nil {
+		t.Error("FromRecord accepted a key stored under another key ID")
+	}
+
+	if _, err := k.Record(""); !errors.Is(err, ErrNoSecret) {
+		t.Errorf("Record without a secret: err = %v, want ErrNoSecret", err)
+	}
+	if _, err := FromRecord(rec, ""); !errors.Is(err, ErrNoSecret) {
+		t.Errorf("FromRecord without a secret: err = %v, want ErrNoSecret", err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	s := &store{}
+	old := mustGenerate(t, EdDSA)
+	s.rotate(t, old, 0)
+	r := newKeyring(s)
+
+	oldToken, err := r.Sign(context.Background(), claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotating to an RSA key, the old key verifies for another hour.
+	next := mustGenerate(t, RS256)
+	s.rotate(t, next, time.Hour)
+	r.loadedAt = time.Time{}
+
+	newToken, err := r.Sign(context.Background(), claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if parsed.Header["kid"] != next.ID {
+		t.Errorf("signed with %v, want the new key %s", parsed.Header["kid"], next.ID)
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := r.Parse(context.Background(), token); err != nil {
+			t.Errorf("%s token rejected during the grace period: %v", name, err)
+		}
+	}
+
+	set, err := r.JWKS(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Keys) != 2 || set.Keys[0].KeyID != next.ID || set.Keys[1].KeyID != old.ID {
+		t.Errorf("JWKS = %+v, want the new and the old key", set)
+	}
+	if k := set.Keys[1]; k.KeyType != "OKP" || k.Curve != "Ed25519" || k.X == "" || k.Use != "sig" || k.Algorithm != EdDSA {
+		t.Errorf("Ed25519 JWK = %+v", k)
+	}
+	if k := set.Keys[0]; k.KeyType != "RSA" || k.N == "" || k.E != "AQAB" || k.Algorithm != RS256 {
+		t.Errorf("RSA JWK = %+v", k)
+	}
+
+	// Once the grace period ends the old key is gone.
+	expired := time.Now().Add(-time.Second)
+	r.keys[1].ExpiresAt = &expired
+	if _, err := r.Parse(context.Background(), oldToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("old token after the grace period: err = %v, want ErrUnknownKey", err)
+	}
+	set, _ = r.JWKS(context.Background())
+	if len(set.Keys) != 1 {
+		t.Errorf("JWKS has %d keys after the grace period, want 1", len(set.Keys))
+	}
+}
+
+func TestReloadOnUnknownKey(t *testing.T) {
+	s := &store{}
+	s.rotate(t, mustGenerate(t, EdDSA), 0)
+	r := newKeyring(s)
+	if _, err := r.JWKS(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another instance rotates and signs with a key this one hasn't loaded yet.
+	other := &store{recs: s.recs}
+	other.rotate(t, mustGenerate(t, EdDSA), time.Hour)
+	token, err := newKeyring(other).Sign(context.Background(), claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.recs = other.recs
+
+	// Reloads are rate limited.
+	if _, err := r.Parse(context.Background(), token); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("err = %v, want ErrUnknownKey right after loading", err)
+	}
+
+	r.loadedAt = time.Now().Add(-minReloadInterval)
+	if _, err := r.Parse(context.Background(), token); err != nil {
+		t.Errorf("token of a new key rejected after reloading: %v", err)
+	}
+	if s.loads != 2 {
+		t.Errorf("loaded %d times, want 2", s.loads)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	s := &store{}
+	k := mustGenerate(t, RS256)
+	s.rotate(t, k, 0)
+	r := newKeyring(s)
+
+	// HS256 signed with the public key, the classic algorithm confusion attack.
+	pub := k.signer.Public().(*rsa.PublicKey)
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	hs.Header["kid"] = k.ID
+	hsToken, err := hs.SignedString(pub.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, claims())
+	none.Header["kid"] = k.ID
+	noneToken, err := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A key of the right type from somewhere else.
+	stranger := mustGenerate(t, RS256)
+	strangerToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims()).SignedString(stranger.signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The right key, but the token expired.
+	expiredClaims := claims()
+	expiredClaims["exp"] = time.Now().Add(-time.Minute).Unix()
+	expiredToken, err := r.Sign(context.Background(), expiredClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"hs256":    hsToken,
+		"none":     noneToken,
+		"stranger": strangerToken,
+		"expired":  expiredToken,
+		"garbage":  "not.a.token",
+	} {
+		if _, err := r.Parse(context.Background(), token); err == nil {
+			t.Errorf("%s token accepted", name)
+		}
+	}
+}
+
+func TestNoKeys(t *testing.T) {
+	r := newKeyring(&store{})
+
+	if _, err := r.Sign(context.Background(), claims()); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Sign: err = %v, want ErrNoKeys", err)
+	}
+	if _, err := r.Parse(context.Background(), "a.b.c"); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Parse: err = %v, want ErrNoKeys", err)
+	}
+
+	set, err := r.JWKS(context.Background())
+	if err != nil || set.Keys == nil || len(set.Keys) != 0 {
+		t.Errorf("JWKS = %+v, %v, want an empty set", set, err)
+	}
+}
+
+func TestLegacyTokens(t *testing.T) {
+	hsToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims()).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &store{}
+	s.rotate(t, mustGenerate(t, EdDSA), 0)
+	r := New(s.load, testSecret, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Left to the caller while tokens signed before the first key may still be around.
+	if _, err := r.Parse(context.Background(), hsToken); !errors.Is(err, ErrLegacyToken) {
+		t.Errorf("within the grace period: err = %v, want ErrLegacyToken", err)
+	}
+
+	r.keys[0].CreatedAt = time.Now().Add(-time.Hour)
+	if _, err := r.Parse(context.Background(), hsToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("after the grace period: err = %v, want ErrUnknownKey", err)
+	}
+}
@@ -0,0 +1,224 @@
+// Package mailer sends plain text emails through SMTP, or writes them to files or
+// the log during development.
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joybiswas007/blog/config"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string // Recipient address
+	Subject string // Subject line
+	Body    string // Plain text body
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// ErrInvalidHeader is returned when the recipient or subject would break the message headers.
+var ErrInvalidHeader = errors.New("mailer: header contains a line break")
+
+// New returns the mailer for the configured transport. The log transport is used
+// when none is configured. siteURL is used to derive the sender if none is set.
+func New(cfg config.Mail, siteURL string, logger *slog.Logger) (Mailer, error) {
+	from := cfg.From
+	if from == "" {
+		from = defaultFrom(siteURL)
+	}
+
+	switch cfg.Transport {
+	case "smtp":
+		if cfg.SMTP.Host == "" {
+			return nil, errors.New("mailer: smtp transport needs mail.smtp.host")
+		}
+		port := cfg.SMTP.Port
+		if port == 0 {
+			port = 587
+		}
+		m := &smtpMailer{
+			addr: net.JoinHostPort(cfg.SMTP.Host, strconv.Itoa(port)),
+			from: from,
+		}
+		// Local stand-ins usually don't do auth.
+		if cfg.SMTP.Username != "" {
+			m.auth = smtp.PlainAuth("", cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.Host)
+		}
+		return m, nil
+	case "file":
+		if cfg.Dir == "" {
+			return nil, errors.New("mailer: file transport needs mail.dir")
+		}
+		if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+			return nil, err
+		}
+		return &fileMailer{dir: cfg.Dir, from: from}, nil
+	case "", "log":
+		return &logMailer{logger: logger, from: from}, nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown transport %q", cfg.Transport)
+	}
+}
+
+// defaultFrom returns no-reply@ the host of siteURL.
+func defaultFrom(siteURL string) string {
+	host := "localhost"
+	if u, err := url.Parse(siteURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return "no-reply@" + host
+}
+
+// Format renders msg as an RFC 5322 message from the given sender.
+func Format(from string, msg Message, date time.Time) ([]byte, error) {
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return nil, ErrInvalidHeader
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	// SMTP wants CRLF line endings in the body too.
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return []byte(b.String()), nil
+}
+
+// smtpMailer sends messages through an SMTP server.
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// Send delivers msg, giving up once ctx is done even in the middle of talking to the server.
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	body, err := Format(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Reads and writes on the connection fail from the deadline on, or as soon as ctx
+	// is cancelled.
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	err = m.send(conn, msg.To, body)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// send runs the SMTP conversation on conn, the way smtp.SendMail does.
+func (m *smtpMailer) send(conn net.Conn, to string, body []byte) error {
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("mailer: smtp server doesn't support AUTH")
+		}
+		if err := c.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(m.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// fileMailer writes each message to its own .eml file.
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+func (m *fileMailer) Send(_ context.Context, msg Message) error {
+	now := time.Now()
+	body, err := Format(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o640)
+}
+
+// logMailer logs that messages would have been sent instead of sending them. The body
+// is left out, it may carry secrets like a password reset link: use the file transport
+// to read messages during development.
+type logMailer struct {
+	logger *slog.Logger
+	from   string
+}
+
+func (m *logMailer) Send(_ context.Context, msg Message) error {
+	m.logger.Info("mail", "from", m.from, "to", msg.To, "subject", msg.Subject, "body_bytes", len(msg.Body))
+	return nil
+}
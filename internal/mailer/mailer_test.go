@@ -0,0 +1,232 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joybiswas007/blog/config"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := Message{To: "jane@example.com", Subject: "Reset your password", Body: "line one\nline two"}
+
+	got, err := Format("no-reply@example.com", msg, date)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "From: no-reply@example.com\r\n" +
+		"To: jane@example.com\r\n" +
+		"Subject: Reset your password\r\n" +
+		"Date: Fri, 02 Jan 2026 03:04:05 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"Content-Transfer-Encoding: 8bit\r\n" +
+		"\r\n" +
+		"line one\r\nline two"
+	if string(got) != want {
+		t.Errorf("Format() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFormatEncodesSubject(t *testing.T) {
+	got, err := Format("a@example.com", Message{To: "b@example.com", Subject: "Grüße"}, time.Now())
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(string(got), "Subject: =?utf-8?q?Gr=C3=BC=C3=9Fe?=\r\n") {
+		t.Errorf("Format() subject not encoded:\n%s", got)
+	}
+}
+
+func TestFormatRejectsHeaderInjection(t *testing.T) {
+	msgs := []Message{
+		{To: "a@example.com\r\nBcc: evil@example.com", Subject: "hi"},
+		{To: "a@example.com", Subject: "hi\nBcc: evil@example.com"},
+	}
+	for _, msg := range msgs {
+		if _, err := Format("a@example.com", msg, time.Now()); !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("Format(%q) error = %v, want ErrInvalidHeader", msg, err)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(config.Mail{Transport: "file", Dir: dir}, "https://blog.example.com", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = m.Send(context.Background(), Message{To: "jane@example.com", Subject: "hi", Body: "hello"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	body, _ := os.ReadFile(dir + "/" + files[0].Name())
+	if !strings.Contains(string(body), "From: no-reply@blog.example.com\r\n") {
+		t.Errorf("message has no default sender:\n%s", body)
+	}
+}
+
+// fakeSMTP accepts a single message without auth, like a local SMTP stand-in,
+// and sends its data to the returned channel.
+func fakeSMTP(t *testing.T) (host string, port int, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				ch <- b.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+func TestSMTPMailer(t *testing.T) {
+	host, port, data := fakeSMTP(t)
+	m, err := New(config.Mail{Transport: "smtp", From: "blog@example.com", SMTP: config.SMTP{Host: host, Port: port}}, "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = m.Send(context.Background(), Message{To: "jane@example.com", Subject: "hi", Body: "hello"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case got := <-data:
+		if !strings.Contains(got, "To: jane@example.com\r\n") || !strings.HasSuffix(got, "hello\r\n") {
+			t.Errorf("server got unexpected message:\n%s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server got no message")
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Mail
+		wantErr bool
+	}{
+		{"default", config.Mail{}, false},
+		{"log", config.Mail{Transport: "log"}, false},
+		{"smtp", config.Mail{Transport: "smtp", SMTP: config.SMTP{Host: "localhost", Port: 1025}}, false},
+		{"smtp without host", config.Mail{Transport: "smtp"}, true},
+		{"file without dir", config.Mail{Transport: "file"}, true},
+		{"unknown", config.Mail{Transport: "pigeon"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg, "https://blog.example.com", nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSMTPMailerGivesUpWithContext(t *testing.T) {
+	// A server that accepts the connection but never greets.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { conn.Close() })
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	m, err := New(config.Mail{Transport: "smtp", SMTP: config.SMTP{Host: addr.IP.String(), Port: addr.Port}}, "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = m.Send(ctx, Message{To: "jane@example.com", Subject: "hi", Body: "hello"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Send() took %v", elapsed)
+	}
+}
+
+func TestLogMailerLeavesOutBody(t *testing.T) {
+	var out strings.Builder
+	m, err := New(config.Mail{}, "https://blog.example.com", slog.New(slog.NewTextHandler(&out, nil)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = m.Send(context.Background(), Message{To: "jane@example.com", Subject: "Reset", Body: "https://blog.example.com/reset-password/secret"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got := out.String(); strings.Contains(got, "secret") || !strings.Contains(got, "jane@example.com") {
+		t.Errorf("log = %q, want the recipient without the body", got)
+	}
+}
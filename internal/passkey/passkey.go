@@ -0,0 +1,247 @@
+// Package passkey runs the WebAuthn registration and login ceremonies for passkeys.
+//
+// Each ceremony has a begin step, which returns the options for the browser along with
+// a session the caller keeps on the server, and a finish step, which checks the
+// browser's response against that session.
+package passkey
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/joybiswas007/blog/config"
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// SessionTTL is how long a ceremony may take between its begin and finish step.
+const SessionTTL = 5 * time.Minute
+
+var (
+	// ErrClonedAuthenticator is returned when the signature counter of a passkey didn't
+	// increase, which means the private key may have been copied.
+	ErrClonedAuthenticator = errors.New("passkey signature counter didn't increase, the authenticator may be cloned")
+
+	// ErrUnknownUser is returned when a passkey belongs to a user who doesn't exist anymore.
+	ErrUnknownUser = errors.New("passkey doesn't belong to any user")
+)
+
+// Service runs the ceremonies for one relying party, i.e. the blog.
+type Service struct {
+	wa *webauthn.WebAuthn
+}
+
+// User adapts a blog user and their passkeys to the WebAuthn library.
+type User struct {
+	ID       int64
+	Name     string
+	Email    string
+	Passkeys []*database.Passkey
+}
+
+// UserLoader loads a user and their passkeys by ID during a login.
+type UserLoader func(userID int64) (*User, error)
+
+// New returns a Service for the blog at siteURL. The relying party ID and origins in cfg
+// default to the host and origin of siteURL.
+func New(cfg config.WebAuthn, siteName, siteURL string) (*Service, error) {
+	site, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blog url: %w", err)
+	}
+
+	rpID := cfg.RPID
+	if rpID == "" {
+		rpID = site.Hostname()
+	}
+
+	origins := cfg.Origins
+	if len(origins) == 0 {
+		origins = []string{site.Scheme + "://" + site.Host}
+	}
+
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: SessionTTL, TimeoutUVD: SessionTTL}
+
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: siteName,
+		RPOrigins:     origins,
+		// Passkeys are discoverable credentials and replace the password, so the
+		// authenticator has to verify the user, e.g. with a fingerprint or PIN.
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{wa: wa}, nil
+}
+
+// BeginRegistration starts adding a passkey for u. Passkeys u already has are excluded,
+// so the same authenticator isn't registered twice.
+func (s *Service) BeginRegistration(u *User) (*protocol.CredentialCreation, *webauthn.SessionData, error) {
+	exclusions := webauthn.Credentials(u.WebAuthnCredentials()).CredentialDescriptors()
+	return s.wa.BeginRegistration(u, webauthn.WithExclusions(exclusions))
+}
+
+// FinishRegistration checks the browser's response to BeginRegistration and returns the
+// new passkey, ready to be stored.
+func (s *Service) FinishRegistration(u *User, session webauthn.SessionData, response []byte) (*database.Passkey, error) {
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := s.wa.CreateCredential(u, session, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	transports := make([]string, len(cred.Transport))
+	for i, t := range cred.Transport {
+		transports[i] = string(t)
+	}
+
+	return &database.Passkey{
+		UserID:            u.ID,
+		CredentialID:      cred.ID,
+		PublicKey:         cred.PublicKey,
+		AttestationType:   cred.AttestationType,
+		AttestationFormat: cred.AttestationFormat,
+		AAGUID:            cred.Authenticator.AAGUID,
+		SignCount:         int64(cred.Authenticator.SignCount),
+		Transports:        transports,
+		UserVerified:      cred.Flags.UserVerified,
+		BackupEligible:    cred.Flags.BackupEligible,
+		BackupState:       cred.Flags.BackupState,
+	}, nil
+}
+
+// BeginLogin starts a login without asking who is signing in, the browser offers the
+// passkeys it has for the blog.
+func (s *Service) BeginLogin() (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+	return s.wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+}
+
+// FinishLogin checks the browser's response to BeginLogin. It returns the user who
+// signed in and their passkey, updated with the new signature counter and flags for
+// the caller to store.
+func (s *Service) FinishLogin(session webauthn.SessionData, response []byte, load UserLoader) (*User, *database.Passkey, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler := func(_, userHandle []byte) (webauthn.User, error) {
+		userID, err := userIDFromHandle(userHandle)
+		if err != nil {
+			return nil, err
+		}
+
+		u, err := load(userID)
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			return nil, ErrUnknownUser
+		}
+		return u, nil
+	}
+
+	wu, cred, err := s.wa.ValidatePasskeyLogin(handler, session, parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cred.Authenticator.CloneWarning {
+		return nil, nil, ErrClonedAuthenticator
+	}
+
+	u := wu.(*User)
+	for _, pk := range u.Passkeys {
+		if bytes.Equal(pk.CredentialID, cred.ID) {
+			pk.SignCount = int64(cred.Authenticator.SignCount)
+			pk.UserVerified = cred.Flags.UserVerified
+			pk.BackupState = cred.Flags.BackupState
+			return u, pk, nil
+		}
+	}
+
+	// ValidatePasskeyLogin only accepts credentials of the user, so this can't happen.
+	return nil, nil, ErrUnknownUser
+}
+
+// UserHandle returns the WebAuthn user handle of a user: their ID as 8 big endian bytes.
+func UserHandle(userID int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(userID))
+}
+
+// userIDFromHandle is the inverse of UserHandle.
+func userIDFromHandle(handle []byte) (int64, error) {
+	if len(handle) != 8 {
+		return 0, ErrUnknownUser
+	}
+	return int64(binary.BigEndian.Uint64(handle)), nil
+}
+
+// WebAuthnID implements webauthn.User.
+func (u *User) WebAuthnID() []byte {
+	return UserHandle(u.ID)
+}
+
+// WebAuthnName implements webauthn.User, authenticators show it to tell accounts apart.
+func (u *User) WebAuthnName() string {
+	return u.Email
+}
+
+// WebAuthnDisplayName implements webauthn.User.
+func (u *User) WebAuthnDisplayName() string {
+	return u.Name
+}
+
+// WebAuthnCredentials implements webauthn.User.
+func (u *User) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, len(u.Passkeys))
+	for i, pk := range u.Passkeys {
+		transports := make([]protocol.AuthenticatorTransport, len(pk.Transports))
+		for j, t := range pk.Transports {
+			transports[j] = protocol.AuthenticatorTransport(t)
+		}
+
+		flags := protocol.FlagUserPresent
+		if pk.UserVerified {
+			flags |= protocol.FlagUserVerified
+		}
+		if pk.BackupEligible {
+			flags |= protocol.FlagBackupEligible
+		}
+		if pk.BackupState {
+			flags |= protocol.FlagBackupState
+		}
+
+		creds[i] = webauthn.Credential{
+			ID:                pk.CredentialID,
+			PublicKey:         pk.PublicKey,
+			AttestationType:   pk.AttestationType,
+			AttestationFormat: pk.AttestationFormat,
+			Transport:         transports,
+			Flags:             webauthn.NewCredentialFlags(flags),
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    pk.AAGUID,
+				SignCount: uint32(pk.SignCount),
+			},
+		}
+	}
+	return creds
+}
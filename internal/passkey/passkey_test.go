@@ -0,0 +1,394 @@
+package passkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+
+	"github.com/joybiswas007/blog/config"
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+const (
+	testRPID   = "blog.example.com"
+	testOrigin = "https://blog.example.com"
+)
+
+// softAuthenticator is an in-memory passkey authenticator, standing in for the
+// browser and a security key or phone.
+type softAuthenticator struct {
+	key        *ecdsa.PrivateKey
+	credID     []byte
+	userHandle []byte
+	signCount  uint32
+	origin     string                      // Origin the "browser" reports, the page that started the ceremony
+	flags      protocol.AuthenticatorFlags // Flags set in every response
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credID := make([]byte, 16)
+	rand.Read(credID)
+
+	return &softAuthenticator{
+		key:    key,
+		credID: credID,
+		origin: testOrigin,
+		flags:  protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagBackupEligible | protocol.FlagBackupState,
+	}
+}
+
+// register answers the options of BeginRegistration like navigator.credentials.create.
+func (a *softAuthenticator) register(t *testing.T, creation *protocol.CredentialCreation, userHandle []byte) []byte {
+	t.Helper()
+
+	a.userHandle = userHandle
+
+	publicKey, err := webauthncbor.Marshal(map[int64]any{
+		1:  int64(webauthncose.EllipticKey),
+		3:  int64(webauthncose.AlgES256),
+		-1: int64(webauthncose.P256),
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Attested credential data: AAGUID, credential ID length and ID, public key.
+	attested := make([]byte, 16)
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credID)))
+	attested = append(attested, a.credID...)
+	attested = append(attested, publicKey...)
+
+	authData := a.authenticatorData(a.flags|protocol.FlagAttestedCredentialData, attested)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.credentialJSON(t, map[string]any{
+		"attestationObject": b64(attestationObject),
+		"clientDataJSON":    b64(a.clientData(t, "webauthn.create", creation.Response.Challenge.String())),
+		"transports":        []string{"internal", "hybrid"},
+	})
+}
+
+// login answers the options of BeginLogin like navigator.credentials.get.
+func (a *softAuthenticator) login(t *testing.T, assertion *protocol.CredentialAssertion) []byte {
+	t.Helper()
+
+	a.signCount++
+	authData := a.authenticatorData(a.flags, nil)
+	clientData := a.clientData(t, "webauthn.get", assertion.Response.Challenge.String())
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.credentialJSON(t, map[string]any{
+		"authenticatorData": b64(authData),
+		"clientDataJSON":    b64(clientData),
+		"signature":         b64(sig),
+		"userHandle":        b64(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) authenticatorData(flags protocol.AuthenticatorFlags, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, byte(flags))
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony, challenge string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"type":        ceremony,
+		"challenge":   challenge,
+		"origin":      a.origin,
+		"crossOrigin": false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func (a *softAuthenticator) credentialJSON(t *testing.T, response map[string]any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"id":                      b64(a.credID),
+		"rawId":                   b64(a.credID),
+		"type":                    "public-key",
+		"authenticatorAttachment": "platform",
+		"clientExtensionResults":  map[string]any{},
+		"response":                response,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	s, err := New(config.WebAuthn{}, "Test Blog", testOrigin)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+// registerPasskey runs a registration ceremony for u with a and adds the new passkey to u.
+func registerPasskey(t *testing.T, s *Service, u *User, a *softAuthenticator) *database.Passkey {
+	t.Helper()
+
+	creation, session, err := s.BeginRegistration(u)
+	if err != nil {
+		t.Fatalf("BeginRegistration() error = %v", err)
+	}
+
+	pk, err := s.FinishRegistration(u, *session, a.register(t, creation, u.WebAuthnID()))
+	if err != nil {
+		t.Fatalf("FinishRegistration() error = %v", err)
+	}
+
+	pk.ID = int64(len(u.Passkeys) + 1)
+	u.Passkeys = append(u.Passkeys, pk)
+	return pk
+}
+
+func loaderFor(users ...*User) UserLoader {
+	return func(userID int64) (*User, error) {
+		for _, u := range users {
+			if u.ID == userID {
+				return u, nil
+			}
+		}
+		return nil, database.ErrRecordNotFound
+	}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	s := newTestService(t)
+	u := &User{ID: 42, Name: "Jane Doe", Email: "jane@example.com"}
+	a := newSoftAuthenticator(t)
+
+	pk := registerPasskey(t, s, u, a)
+
+	if pk.UserID != 42 || string(pk.CredentialID) != string(a.credID) {
+		t.Errorf("FinishRegistration() = user %d, credential %x; want user 42, credential %x", pk.UserID, pk.CredentialID, a.credID)
+	}
+	if !pk.UserVerified || !pk.BackupEligible || !pk.BackupState {
+		t.Errorf("FinishRegistration() flags = uv %v, be %v, bs %v; want all set", pk.UserVerified, pk.BackupEligible, pk.BackupState)
+	}
+	if len(pk.Transports) != 2 {
+		t.Errorf("FinishRegistration() transports = %v, want [internal hybrid]", pk.Transports)
+	}
+
+	for i := range 2 {
+		assertion, session, err := s.BeginLogin()
+		if err != nil {
+			t.Fatalf("BeginLogin() error = %v", err)
+		}
+		if len(assertion.Response.AllowedCredentials) != 0 {
+			t.Errorf("BeginLogin() allowed credentials = %v, want none for a discoverable login", assertion.Response.AllowedCredentials)
+		}
+
+		gotUser, gotPasskey, err := s.FinishLogin(*session, a.login(t, assertion), loaderFor(u))
+		if err != nil {
+			t.Fatalf("FinishLogin() #%d error = %v", i, err)
+		}
+		if gotUser.ID != u.ID || gotPasskey.ID != pk.ID {
+			t.Errorf("FinishLogin() = user %d, passkey %d; want user %d, passkey %d", gotUser.ID, gotPasskey.ID, u.ID, pk.ID)
+		}
+		if gotPasskey.SignCount != int64(a.signCount) {
+			t.Errorf("FinishLogin() sign count = %d, want %d", gotPasskey.SignCount, a.signCount)
+		}
+	}
+}
+
+func TestRegisterExcludesExistingPasskeys(t *testing.T) {
+	s := newTestService(t)
+	u := &User{ID: 1, Name: "Jane Doe", Email: "jane@example.com"}
+	registerPasskey(t, s, u, newSoftAuthenticator(t))
+
+	creation, _, err := s.BeginRegistration(u)
+	if err != nil {
+		t.Fatalf("BeginRegistration() error = %v", err)
+	}
+	if len(creation.Response.CredentialExcludeList) != 1 {
+		t.Errorf("BeginRegistration() excludes %d credentials, want 1", len(creation.Response.CredentialExcludeList))
+	}
+}
+
+func TestRegisterRejectsWrongOrigin(t *testing.T) {
+	s := newTestService(t)
+	u := &User{ID: 1, Name: "Jane Doe", Email: "jane@example.com"}
+	a := newSoftAuthenticator(t)
+	a.origin = "https://blog.example.com.evil.test"
+
+	creation, session, err := s.BeginRegistration(u)
+	if err != nil {
+		t.Fatalf("BeginRegistration() error = %v", err)
+	}
+
+	if _, err := s.FinishRegistration(u, *session, a.register(t, creation, u.WebAuthnID())); err == nil {
+		t.Error("FinishRegistration() accepted a response from another origin")
+	}
+}
+
+func TestLoginFailures(t *testing.T) {
+	s := newTestService(t)
+	u := &User{ID: 7, Name: "Jane Doe", Email: "jane@example.com"}
+
+	tests := []struct {
+		name    string
+		prepare func(a *softAuthenticator)
+		load    UserLoader
+		wantErr error
+	}{
+		{
+			name:    "phishing origin",
+			prepare: func(a *softAuthenticator) { a.origin = "https://blog-example.evil.test" },
+			load:    loaderFor(u),
+		},
+		{
+			name:    "user not verified",
+			prepare: func(a *softAuthenticator) { a.flags &^= protocol.FlagUserVerified },
+			load:    loaderFor(u),
+		},
+		{
+			name:    "cloned authenticator",
+			prepare: func(a *softAuthenticator) { a.signCount = 0; u.Passkeys[len(u.Passkeys)-1].SignCount = 10 },
+			load:    loaderFor(u),
+			wantErr: ErrClonedAuthenticator,
+		},
+		{
+			name:    "deleted user",
+			prepare: func(a *softAuthenticator) {},
+			load:    loaderFor(),
+		},
+		{
+			name: "unknown credential",
+			prepare: func(a *softAuthenticator) {
+				a.credID = []byte("some other credential")
+			},
+			load: loaderFor(u),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newSoftAuthenticator(t)
+			registerPasskey(t, s, u, a)
+			tt.prepare(a)
+
+			assertion, session, err := s.BeginLogin()
+			if err != nil {
+				t.Fatalf("BeginLogin() error = %v", err)
+			}
+
+			_, _, err = s.FinishLogin(*session, a.login(t, assertion), tt.load)
+			if err == nil {
+				t.Fatal("FinishLogin() succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("FinishLogin() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginRejectsOtherChallenge(t *testing.T) {
+	s := newTestService(t)
+	u := &User{ID: 3, Name: "Jane Doe", Email: "jane@example.com"}
+	a := newSoftAuthenticator(t)
+	registerPasskey(t, s, u, a)
+
+	first, _, err := s.BeginLogin()
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	_, second, err := s.BeginLogin()
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+
+	// A response signed for one ceremony can't finish another one.
+	if _, _, err := s.FinishLogin(*second, a.login(t, first), loaderFor(u)); err == nil {
+		t.Error("FinishLogin() accepted a response to another challenge")
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.WebAuthn
+		wantRPID    string
+		wantOrigins []string
+	}{
+		{"derived from blog url", config.WebAuthn{}, "blog.example.com", []string{"https://blog.example.com"}},
+		{"configured", config.WebAuthn{RPID: "example.com", Origins: []string{"http://localhost:5173"}}, "example.com", []string{"http://localhost:5173"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.cfg, "Test Blog", "https://blog.example.com/")
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if s.wa.Config.RPID != tt.wantRPID {
+				t.Errorf("New() RPID = %q, want %q", s.wa.Config.RPID, tt.wantRPID)
+			}
+			if len(s.wa.Config.RPOrigins) != len(tt.wantOrigins) || s.wa.Config.RPOrigins[0] != tt.wantOrigins[0] {
+				t.Errorf("New() origins = %v, want %v", s.wa.Config.RPOrigins, tt.wantOrigins)
+			}
+		})
+	}
+}
+
+func TestUserHandle(t *testing.T) {
+	for _, id := range []int64{1, 42, 1 << 40} {
+		got, err := userIDFromHandle(UserHandle(id))
+		if err != nil || got != id {
+			t.Errorf("userIDFromHandle(UserHandle(%d)) = %d, %v", id, got, err)
+		}
+	}
+
+	if _, err := userIDFromHandle([]byte("short")); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("userIDFromHandle() error = %v, want ErrUnknownUser", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package publisher runs the background worker that publishes scheduled posts.
+package publisher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/chenyahui/gin-cache/persist"
+
+	"github.com/joybiswas007/blog/internal/cache"
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// DefaultInterval is how often due posts are checked when no interval is configured.
+const DefaultInterval = time.Minute
+
+// Publisher periodically publishes drafts whose publish_at time has passed.
+type Publisher struct {
+	posts      database.PostModel  // Post model used to publish due drafts
+	cacheStore *persist.RedisStore // Response cache cleared after publishing
+	logger     *slog.Logger
+	interval   time.Duration // Time between two runs
+}
+
+// New creates a new Publisher. A non-positive interval falls back to DefaultInterval.
+func New(posts database.PostModel, cacheStore *persist.RedisStore, logger *slog.Logger, interval time.Duration) *Publisher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Publisher{
+		posts:      posts,
+		cacheStore: cacheStore,
+		logger:     logger,
+		interval:   interval,
+	}
+}
+
+// Run publishes due posts once immediately and then on every tick until ctx is cancelled.
+func (p *Publisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.publishDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDue publishes all due posts and clears the response cache if anything went live.
+func (p *Publisher) publishDue(ctx context.Context) {
+	postIDs, err := p.posts.PublishDue(ctx)
+	if err != nil {
+		p.logger.Error("failed to publish scheduled posts", "error", err)
+		return
+	}
+
+	if len(postIDs) == 0 {
+		return
+	}
+
+	p.logger.Info("published scheduled posts", "post_ids", postIDs)
+
+	// delete cache keys so the new posts show up in listings
+	cache.Invalidate(p.cacheStore)
+}
@@ -0,0 +1,5 @@
+ALTER TABLE "blog_posts" ADD COLUMN "publish_at" TIMESTAMPTZ;
+
+-- Partial index used by the scheduled publisher to find due drafts.
+CREATE INDEX idx_blog_posts_publish_at ON "blog_posts"("publish_at")
+WHERE "is_published" = false AND "publish_at" IS NOT NULL;
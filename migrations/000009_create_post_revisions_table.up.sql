@@ -0,0 +1,27 @@
+CREATE TABLE "post_revisions" (
+	"id" SERIAL NOT NULL UNIQUE,
+	"post_id" INTEGER NOT NULL,
+	"user_id" INTEGER,
+	"title" VARCHAR(255) NOT NULL,
+	"description" VARCHAR(255),
+	"content" TEXT NOT NULL,
+	"slug" VARCHAR(255) NOT NULL,
+	"tags" TEXT[] NOT NULL DEFAULT '{}',
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX idx_post_revisions_post_id ON post_revisions(post_id);
+
+-- Foreign key: post_revisions.post_id -> blog_posts.id
+ALTER TABLE "post_revisions"
+ADD CONSTRAINT fk_post_revisions_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Foreign key: post_revisions.user_id -> users.id
+-- Keep the revision when its author is removed.
+ALTER TABLE "post_revisions"
+ADD CONSTRAINT fk_post_revisions_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE SET NULL;
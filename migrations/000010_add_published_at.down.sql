@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_blog_posts_published_at;
+ALTER TABLE "blog_posts" DROP COLUMN IF EXISTS "published_at";
@@ -0,0 +1,6 @@
+ALTER TABLE "blog_posts" ADD COLUMN "published_at" TIMESTAMPTZ;
+
+-- Until now created_at was rewritten on publish, so it holds the publish date of live posts.
+UPDATE "blog_posts" SET "published_at" = "created_at" WHERE "is_published" = true;
+
+CREATE INDEX idx_blog_posts_published_at ON "blog_posts"("published_at");
@@ -0,0 +1,15 @@
+-- Retired slugs of posts, used to redirect old links to the current slug.
+CREATE TABLE "post_slugs" (
+	"slug" VARCHAR(255) NOT NULL UNIQUE,
+	"post_id" INTEGER NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("slug")
+);
+
+CREATE INDEX idx_post_slugs_post_id ON post_slugs(post_id);
+
+-- Foreign key: post_slugs.post_id -> blog_posts.id
+ALTER TABLE "post_slugs"
+ADD CONSTRAINT fk_post_slugs_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- Posts are identified by their slug, titles may repeat.
+ALTER TABLE "blog_posts" DROP CONSTRAINT IF EXISTS "blog_posts_title_key";
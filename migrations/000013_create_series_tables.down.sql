@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS series_posts;
+DROP TRIGGER IF EXISTS update_series_updated_at ON "series";
+DROP TABLE IF EXISTS series;
@@ -0,0 +1,36 @@
+CREATE TABLE "series" (
+	"id" SERIAL NOT NULL UNIQUE,
+	"title" VARCHAR(255) NOT NULL,
+	"slug" VARCHAR(255) NOT NULL UNIQUE,
+	"description" TEXT,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+
+-- Ordered membership of posts in a series. A post can be part of one series only.
+CREATE TABLE "series_posts" (
+	"series_id" INTEGER NOT NULL,
+	"post_id" INTEGER NOT NULL UNIQUE,
+	"position" INTEGER NOT NULL,
+	PRIMARY KEY("series_id", "post_id"),
+	UNIQUE("series_id", "position")
+);
+
+-- Trigger for series table
+CREATE TRIGGER update_series_updated_at
+BEFORE UPDATE ON "series"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Foreign key: series_posts.series_id -> series.id
+ALTER TABLE "series_posts"
+ADD CONSTRAINT fk_series_posts_series
+FOREIGN KEY ("series_id") REFERENCES "series"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Foreign key: series_posts.post_id -> blog_posts.id
+ALTER TABLE "series_posts"
+ADD CONSTRAINT fk_series_posts_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+ALTER TABLE "blog_posts" ADD COLUMN "deleted_at" TIMESTAMPTZ;
+
+-- Partial index used to list and empty the trash.
+CREATE INDEX idx_blog_posts_deleted_at ON "blog_posts"("deleted_at")
+WHERE "deleted_at" IS NOT NULL;
@@ -0,0 +1,12 @@
+ALTER TABLE "blog_posts" ADD COLUMN "is_published" BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Unlisted and private posts can't be expressed anymore, they fall back to drafts.
+UPDATE "blog_posts" SET "is_published" = ("visibility" = 'published');
+
+DROP INDEX IF EXISTS idx_blog_posts_visibility;
+DROP INDEX IF EXISTS idx_blog_posts_publish_at;
+CREATE INDEX idx_blog_posts_publish_at ON "blog_posts"("publish_at")
+WHERE "is_published" = false AND "publish_at" IS NOT NULL;
+
+ALTER TABLE "blog_posts" DROP CONSTRAINT IF EXISTS "blog_posts_visibility_check";
+ALTER TABLE "blog_posts" DROP COLUMN IF EXISTS "visibility";
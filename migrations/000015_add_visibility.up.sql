@@ -0,0 +1,15 @@
+ALTER TABLE "blog_posts" ADD COLUMN "visibility" VARCHAR(16) NOT NULL DEFAULT 'draft';
+
+UPDATE "blog_posts" SET "visibility" = 'published' WHERE "is_published" = true;
+
+ALTER TABLE "blog_posts" ADD CONSTRAINT "blog_posts_visibility_check"
+CHECK ("visibility" IN ('draft', 'unlisted', 'private', 'published'));
+
+-- The scheduled publisher index depends on is_published, rebuild it on visibility.
+DROP INDEX IF EXISTS idx_blog_posts_publish_at;
+CREATE INDEX idx_blog_posts_publish_at ON "blog_posts"("publish_at")
+WHERE "visibility" = 'draft' AND "publish_at" IS NOT NULL;
+
+CREATE INDEX idx_blog_posts_visibility ON "blog_posts"("visibility");
+
+ALTER TABLE "blog_posts" DROP COLUMN "is_published";
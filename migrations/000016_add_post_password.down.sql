@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS blog_posts_search_idx;
+CREATE INDEX IF NOT EXISTS blog_posts_search_idx ON "blog_posts" USING gin (
+    (
+        setweight(to_tsvector('simple', "title"), 'A') ||
+        setweight(to_tsvector('simple', COALESCE("description", '')), 'B') ||
+        setweight(to_tsvector('simple', "content"), 'C')
+    )
+);
+
+DROP TABLE IF EXISTS "post_unlock_attempts";
+ALTER TABLE "blog_posts" DROP COLUMN IF EXISTS "password_version";
+ALTER TABLE "blog_posts" DROP COLUMN IF EXISTS "password_hash";
@@ -0,0 +1,33 @@
+ALTER TABLE "blog_posts" ADD COLUMN "password_hash" BYTEA;
+
+-- Incremented whenever the password of a post changes, unlock tokens issued for an
+-- older password stop working.
+ALTER TABLE "blog_posts" ADD COLUMN "password_version" INTEGER NOT NULL DEFAULT 0;
+
+-- Attempts to unlock a password protected post, per post and address. Like logins,
+-- an address is banned after too many wrong passwords.
+CREATE TABLE "post_unlock_attempts" (
+	"post_id" INTEGER NOT NULL,
+	"ip" INET NOT NULL,
+	"attempts" INTEGER NOT NULL DEFAULT 1,
+	"last_attempt" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"banned_until" TIMESTAMPTZ,
+	PRIMARY KEY("post_id", "ip")
+);
+
+-- Foreign key: post_unlock_attempts.post_id -> blog_posts.id
+ALTER TABLE "post_unlock_attempts"
+ADD CONSTRAINT fk_post_unlock_attempts_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- The content of protected posts isn't searchable, the index has to match the
+-- expression PostModel.Search uses.
+DROP INDEX IF EXISTS blog_posts_search_idx;
+CREATE INDEX IF NOT EXISTS blog_posts_search_idx ON "blog_posts" USING gin (
+    (
+        setweight(to_tsvector('simple', "title"), 'A') ||
+        setweight(to_tsvector('simple', COALESCE("description", '')), 'B') ||
+        setweight(to_tsvector('simple', CASE WHEN "password_hash" IS NULL THEN "content" ELSE '' END), 'C')
+    )
+);
@@ -0,0 +1,24 @@
+-- Expiring links that let anyone holding the token read an unpublished post.
+CREATE TABLE "post_previews" (
+	"id" SERIAL NOT NULL UNIQUE,
+	"post_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"token_hash" BYTEA NOT NULL UNIQUE,
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX idx_post_previews_post_id ON post_previews(post_id);
+
+-- Foreign key: post_previews.post_id -> blog_posts.id
+ALTER TABLE "post_previews"
+ADD CONSTRAINT fk_post_previews_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Foreign key: post_previews.user_id -> users.id
+ALTER TABLE "post_previews"
+ADD CONSTRAINT fk_post_previews_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,27 @@
+-- Work in progress of a post, one buffer per post and editor. The live post is
+-- only changed when a buffer is applied.
+CREATE TABLE "post_autosaves" (
+	"post_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"title" VARCHAR(255) NOT NULL DEFAULT '',
+	"description" VARCHAR(255),
+	"content" TEXT NOT NULL DEFAULT '',
+	"slug" VARCHAR(255) NOT NULL DEFAULT '',
+	-- NULL until the editor touches the tags, applying the buffer leaves them alone then.
+	"tags" TEXT[],
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("post_id", "user_id")
+);
+
+-- Foreign key: post_autosaves.post_id -> blog_posts.id
+ALTER TABLE "post_autosaves"
+ADD CONSTRAINT fk_post_autosaves_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Foreign key: post_autosaves.user_id -> users.id
+ALTER TABLE "post_autosaves"
+ADD CONSTRAINT fk_post_autosaves_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
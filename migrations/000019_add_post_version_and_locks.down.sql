@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "post_locks";
+ALTER TABLE "blog_posts" DROP COLUMN IF EXISTS "version";
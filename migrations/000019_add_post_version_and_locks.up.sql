@@ -0,0 +1,23 @@
+-- Incremented on every content update, used for optimistic concurrency control.
+ALTER TABLE "blog_posts" ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
+
+-- Advisory edit locks, they only warn other editors and expire on their own.
+CREATE TABLE "post_locks" (
+	"post_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("post_id")
+);
+
+-- Foreign key: post_locks.post_id -> blog_posts.id
+ALTER TABLE "post_locks"
+ADD CONSTRAINT fk_post_locks_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Foreign key: post_locks.user_id -> users.id
+ALTER TABLE "post_locks"
+ADD CONSTRAINT fk_post_locks_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
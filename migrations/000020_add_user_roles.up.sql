@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "role" VARCHAR(16) NOT NULL DEFAULT 'author';
+
+-- Every existing user could manage all posts so far, keep it that way.
+UPDATE "users" SET "role" = 'admin';
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check"
+CHECK ("role" IN ('admin', 'editor', 'author', 'contributor'));
@@ -0,0 +1,25 @@
+-- Authors of a post in byline order, blog_posts.user_id stays the owner who created it.
+CREATE TABLE "post_authors" (
+	"post_id" INTEGER NOT NULL,
+	"user_id" INTEGER NOT NULL,
+	"position" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("post_id", "user_id")
+);
+
+CREATE INDEX "post_authors_user_id_idx" ON "post_authors" ("user_id");
+
+-- Foreign key: post_authors.post_id -> blog_posts.id
+ALTER TABLE "post_authors"
+ADD CONSTRAINT fk_post_authors_post
+FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Foreign key: post_authors.user_id -> users.id
+ALTER TABLE "post_authors"
+ADD CONSTRAINT fk_post_authors_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Every existing post was written by its owner alone.
+INSERT INTO "post_authors" ("post_id", "user_id", "position")
+SELECT "id", "user_id", 0 FROM "blog_posts";
@@ -0,0 +1,6 @@
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_handle_key";
+ALTER TABLE "users"
+DROP COLUMN IF EXISTS "handle",
+DROP COLUMN IF EXISTS "bio",
+DROP COLUMN IF EXISTS "avatar_url",
+DROP COLUMN IF EXISTS "links";
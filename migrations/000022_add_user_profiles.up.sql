@@ -0,0 +1,23 @@
+-- Public author profile, the handle is the URL-safe name used in /authors/:handle.
+ALTER TABLE "users"
+ADD COLUMN "handle" VARCHAR(64),
+ADD COLUMN "bio" TEXT NOT NULL DEFAULT '',
+ADD COLUMN "avatar_url" TEXT NOT NULL DEFAULT '',
+ADD COLUMN "links" JSONB NOT NULL DEFAULT '[]';
+
+-- Derive handles for existing users from their names.
+UPDATE "users"
+SET "handle" = TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE("name", '[^a-zA-Z0-9]+', '-', 'g')));
+
+UPDATE "users" SET "handle" = 'user-' || "id" WHERE "handle" = '';
+
+-- Users sharing a name keep the handle apart with their ID.
+UPDATE "users" u
+SET "handle" = u."handle" || '-' || u."id"
+WHERE EXISTS (
+	SELECT 1 FROM "users" o WHERE o."handle" = u."handle" AND o."id" < u."id"
+);
+
+ALTER TABLE "users" ALTER COLUMN "handle" SET NOT NULL;
+
+ALTER TABLE "users" ADD CONSTRAINT "users_handle_key" UNIQUE ("handle");
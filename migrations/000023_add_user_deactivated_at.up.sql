@@ -0,0 +1,2 @@
+-- Deactivated users keep their posts but can no longer sign in.
+ALTER TABLE "users" ADD COLUMN "deactivated_at" TIMESTAMPTZ;
@@ -0,0 +1,39 @@
+-- Single-use invitations, only the hash of the token is stored.
+CREATE TABLE "invitations" (
+	"id" serial NOT NULL UNIQUE,
+	"email" TEXT NOT NULL,
+	"role" VARCHAR(16) NOT NULL CHECK ("role" IN ('admin', 'editor', 'author', 'contributor')),
+	"token_hash" BYTEA NOT NULL UNIQUE,
+	"invited_by" INTEGER,
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"accepted_at" TIMESTAMPTZ,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+
+-- Foreign key: invitations.invited_by -> users.id
+ALTER TABLE "invitations"
+ADD CONSTRAINT fk_invitations_user
+FOREIGN KEY ("invited_by") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE SET NULL;
+
+-- Who did what to which account, kept when the actor is deleted.
+CREATE TABLE "audit_logs" (
+	"id" bigserial NOT NULL UNIQUE,
+	"user_id" INTEGER,
+	"action" VARCHAR(64) NOT NULL,
+	"target_type" VARCHAR(32) NOT NULL,
+	"target_id" BIGINT,
+	"details" JSONB NOT NULL DEFAULT '{}',
+	"ip" TEXT NOT NULL DEFAULT '0.0.0.0',
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "audit_logs_created_at_idx" ON "audit_logs" ("created_at" DESC);
+
+-- Foreign key: audit_logs.user_id -> users.id
+ALTER TABLE "audit_logs"
+ADD CONSTRAINT fk_audit_logs_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE SET NULL;
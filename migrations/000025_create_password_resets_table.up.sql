@@ -0,0 +1,18 @@
+-- Single-use password reset links, only the hash of the token is stored.
+CREATE TABLE "password_resets" (
+	"id" serial NOT NULL UNIQUE,
+	"user_id" INTEGER NOT NULL,
+	"token_hash" BYTEA NOT NULL UNIQUE,
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"used_at" TIMESTAMPTZ,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "password_resets_user_id_idx" ON "password_resets" ("user_id");
+
+-- Foreign key: password_resets.user_id -> users.id
+ALTER TABLE "password_resets"
+ADD CONSTRAINT fk_password_resets_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,23 @@
+-- TOTP two-factor authentication. The secret is stored as soon as enrollment starts,
+-- two-factor auth is only enforced once totp_enabled_at is set.
+ALTER TABLE "users" ADD COLUMN "totp_secret" TEXT;
+ALTER TABLE "users" ADD COLUMN "totp_enabled_at" TIMESTAMPTZ;
+-- Last time step a code was accepted for, older and equal steps are rejected so codes can't be replayed.
+ALTER TABLE "users" ADD COLUMN "totp_last_step" BIGINT NOT NULL DEFAULT 0;
+
+-- Single-use recovery codes, only the hash of each code is stored.
+CREATE TABLE "recovery_codes" (
+	"id" serial NOT NULL UNIQUE,
+	"user_id" INTEGER NOT NULL,
+	"code_hash" BYTEA NOT NULL,
+	"used_at" TIMESTAMPTZ,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id"),
+	UNIQUE("user_id", "code_hash")
+);
+
+-- Foreign key: recovery_codes.user_id -> users.id
+ALTER TABLE "recovery_codes"
+ADD CONSTRAINT fk_recovery_codes_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,28 @@
+-- WebAuthn credentials (passkeys) users sign in with, several per user.
+CREATE TABLE "passkeys" (
+	"id" serial NOT NULL UNIQUE,
+	"user_id" INTEGER NOT NULL,
+	"name" VARCHAR(100) NOT NULL DEFAULT '',
+	"credential_id" BYTEA NOT NULL UNIQUE,
+	"public_key" BYTEA NOT NULL,
+	"attestation_type" TEXT NOT NULL DEFAULT '',
+	"attestation_format" TEXT NOT NULL DEFAULT '',
+	"aaguid" BYTEA,
+	-- Signature counter reported by the authenticator, a counter going backwards hints at a cloned key.
+	"sign_count" BIGINT NOT NULL DEFAULT 0,
+	"transports" TEXT[] NOT NULL DEFAULT '{}',
+	"user_verified" BOOLEAN NOT NULL DEFAULT FALSE,
+	"backup_eligible" BOOLEAN NOT NULL DEFAULT FALSE,
+	"backup_state" BOOLEAN NOT NULL DEFAULT FALSE,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"last_used_at" TIMESTAMPTZ,
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "passkeys_user_id_idx" ON "passkeys" ("user_id");
+
+-- Foreign key: passkeys.user_id -> users.id
+ALTER TABLE "passkeys"
+ADD CONSTRAINT fk_passkeys_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
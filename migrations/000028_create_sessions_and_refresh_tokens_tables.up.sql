@@ -0,0 +1,51 @@
+-- Signed in sessions, one per login. Revoking a session rejects its access tokens
+-- right away and stops its refresh tokens from rotating.
+CREATE TABLE "sessions" (
+	"id" serial NOT NULL UNIQUE,
+	"user_id" INTEGER NOT NULL,
+	"ip" TEXT NOT NULL DEFAULT '',
+	"user_agent" TEXT NOT NULL DEFAULT '',
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"last_seen_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	-- Moves forward with every refresh, the session ends when its refresh token expires.
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"revoked_at" TIMESTAMPTZ,
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "sessions_user_id_idx" ON "sessions" ("user_id");
+
+-- Foreign key: sessions.user_id -> users.id
+ALTER TABLE "sessions"
+ADD CONSTRAINT fk_sessions_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Refresh tokens handed out to signed in sessions. Every refresh rotates the token, the
+-- rotated ones are kept so their reuse can be spotted and the whole session revoked.
+CREATE TABLE "refresh_tokens" (
+	"id" serial NOT NULL UNIQUE,
+	"user_id" INTEGER NOT NULL,
+	"session_id" INTEGER NOT NULL,
+	"token_hash" BYTEA NOT NULL UNIQUE,
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	-- Set once the token was exchanged for a new one.
+	"rotated_at" TIMESTAMPTZ,
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "refresh_tokens_user_id_idx" ON "refresh_tokens" ("user_id");
+CREATE INDEX "refresh_tokens_session_id_idx" ON "refresh_tokens" ("session_id");
+
+-- Foreign key: refresh_tokens.user_id -> users.id
+ALTER TABLE "refresh_tokens"
+ADD CONSTRAINT fk_refresh_tokens_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- Foreign key: refresh_tokens.session_id -> sessions.id
+ALTER TABLE "refresh_tokens"
+ADD CONSTRAINT fk_refresh_tokens_session
+FOREIGN KEY ("session_id") REFERENCES "sessions"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
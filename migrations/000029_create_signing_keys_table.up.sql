@@ -0,0 +1,17 @@
+-- Asymmetric keys JWTs are signed with. The newest key signs, rotated out keys keep
+-- verifying tokens until they expire.
+CREATE TABLE "signing_keys" (
+	"id" serial NOT NULL UNIQUE,
+	-- Key ID in the header of the tokens, the RFC 7638 thumbprint of the public key.
+	"kid" TEXT NOT NULL UNIQUE,
+	"algorithm" TEXT NOT NULL,
+	-- PKCS #8 encoded private key, encrypted with AES-256-GCM under jwt.key_secret.
+	"private_key" BYTEA NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	-- NULL for the key that signs, set once it's rotated out.
+	"expires_at" TIMESTAMPTZ,
+	PRIMARY KEY("id")
+);
+
+-- Only one key signs at a time.
+CREATE UNIQUE INDEX "signing_keys_active_idx" ON "signing_keys" (("expires_at" IS NULL)) WHERE "expires_at" IS NULL;
@@ -0,0 +1,24 @@
+-- Long-lived tokens for automation, e.g. a CI pipeline publishing posts. They act as
+-- their user, limited to their scopes.
+CREATE TABLE "personal_access_tokens" (
+	"id" serial NOT NULL UNIQUE,
+	"user_id" INTEGER NOT NULL,
+	"name" VARCHAR(100) NOT NULL,
+	"token_hash" BYTEA NOT NULL UNIQUE,
+	-- Start of the token, shown so users can tell their tokens apart.
+	"hint" TEXT NOT NULL,
+	"scopes" TEXT[] NOT NULL DEFAULT '{}',
+	"expires_at" TIMESTAMPTZ NOT NULL,
+	"last_used_at" TIMESTAMPTZ,
+	"last_used_ip" TEXT NOT NULL DEFAULT '',
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+
+CREATE INDEX "personal_access_tokens_user_id_idx" ON "personal_access_tokens" ("user_id");
+
+-- Foreign key: personal_access_tokens.user_id -> users.id
+ALTER TABLE "personal_access_tokens"
+ADD CONSTRAINT fk_personal_access_tokens_user
+FOREIGN KEY ("user_id") REFERENCES "users"("id")
+ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// tokenBytes is the amount of randomness in a token generated by GenerateToken.
+const tokenBytes = 32
+
+// GenerateToken returns a random, URL safe token suitable for links and API secrets.
+// Tokens should be stored as HashToken(token), never in plain text.
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 hash of a token for storage and lookups.
+// Tokens carry enough randomness that a fast hash is sufficient, unlike passwords.
+func HashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,36 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGenerateToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 100 {
+		token, err := GenerateToken()
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		// 32 bytes of base64 without padding.
+		if len(token) != 43 {
+			t.Errorf("GenerateToken() length = %d, want 43", len(token))
+		}
+		if seen[token] {
+			t.Fatalf("GenerateToken() returned duplicate token %q", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	want, _ := hex.DecodeString("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	if got := HashToken(""); !bytes.Equal(got, want) {
+		t.Errorf("HashToken(\"\") = %x, want %x", got, want)
+	}
+
+	if bytes.Equal(HashToken("a"), HashToken("b")) {
+		t.Error("HashToken() returned the same hash for different tokens")
+	}
+}
@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcKey is the shared secret of the RFC 4226 and RFC 6238 SHA-1 test vectors.
+var rfcKey = []byte("12345678901234567890")
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226, appendix D.
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+	for counter, code := range want {
+		if got := hotp(rfcKey, uint64(counter), 6); got != code {
+			t.Errorf("hotp(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestHOTPRFC6238(t *testing.T) {
+	// RFC 6238, appendix B, SHA-1 rows.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		step := TOTPStep(time.Unix(tt.unix, 0))
+		if got := hotp(rfcKey, uint64(step), 8); got != tt.want {
+			t.Errorf("hotp(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString(rfcKey)
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+
+	code, err := TOTPCode(secret, step)
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+	if code != "050471" {
+		t.Fatalf("TOTPCode() = %s, want 050471", code)
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		at       time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current period", code, now, step, true},
+		{"with spaces", "050 471", now, step, true},
+		{"one period late", code, now.Add(TOTPPeriod * time.Second), step, true},
+		{"one period early", code, now.Add(-TOTPPeriod * time.Second), step, true},
+		{"two periods late", code, now.Add(2 * TOTPPeriod * time.Second), 0, false},
+		{"wrong code", "123456", now, 0, false},
+		{"too short", "05047", now, 0, false},
+		{"empty", "", now, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(secret, tt.code, tt.at)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := ValidateTOTP("not base32!", code, now); ok {
+		t.Error("ValidateTOTP() accepted an invalid secret")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	// 20 bytes of unpadded base32.
+	if len(secret) != 32 {
+		t.Errorf("GenerateTOTPSecret() length = %d, want 32", len(secret))
+	}
+	if _, err := TOTPCode(strings.ToLower(secret), 1); err != nil {
+		t.Errorf("TOTPCode() rejected lower case secret: %v", err)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("My Blog", "jane@example.com", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("TOTPURI() = %s, want otpauth://totp/...", uri)
+	}
+	if u.Path != "/My Blog:jane@example.com" {
+		t.Errorf("TOTPURI() label = %q", u.Path)
+	}
+
+	q := u.Query()
+	if q.Get("secret") != "JBSWY3DPEHPK3PXP" || q.Get("issuer") != "My Blog" {
+		t.Errorf("TOTPURI() query = %v", q)
+	}
+	if q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("TOTPURI() query = %v", q)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes, want 10", len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("recovery code %q isn't formatted as XXXXX-XXXXX", code)
+		}
+		if strings.ContainsAny(code, "01ILO") {
+			t.Errorf("recovery code %q contains an ambiguous character", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	for _, in := range []string{"ABCDE-FGHJK", "abcde-fghjk", "ABCDEFGHJK", " abcde fghjk "} {
+		if got := NormalizeRecoveryCode(in); got != "ABCDEFGHJK" {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want ABCDEFGHJK", in, got)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// accessTokenPrefix starts every personal access token, so they're told apart from JWTs
+// and easy to spot when they leak, e.g. by secret scanners.
+const accessTokenPrefix = "blog_pat_"
+
+// accessTokenHintLength is how much of a token after its prefix is kept as its hint.
+const accessTokenHintLength = 6
+
+// defaultAccessTokenDays is how long a personal access token lasts unless asked otherwise.
+// At most 365 days can be asked for.
+const defaultAccessTokenDays = 90
+
+// Scopes of personal access tokens.
+const (
+	scopePostsRead    = "posts:read"    // List and read posts, drafts included
+	scopePostsWrite   = "posts:write"   // Create and edit posts
+	scopePostsPublish = "posts:publish" // Publish, unpublish, change the visibility of and delete posts
+	scopeTagsAdmin    = "tags:admin"    // List and delete tags
+)
+
+// accessTokenScopes lists the scopes a token can be given, with the roles that may use
+// them. Scopes without roles are open to everyone.
+var accessTokenScopes = map[string][]string{
+	scopePostsRead:    nil,
+	scopePostsWrite:   nil,
+	scopePostsPublish: publisherRoles,
+	scopeTagsAdmin:    publisherRoles,
+}
+
+// impliedScopes lists the scopes that come with another one. Changing posts is hardly
+// possible without reading them.
+var impliedScopes = map[string][]string{
+	scopePostsWrite:   {scopePostsRead},
+	scopePostsPublish: {scopePostsRead},
+}
+
+// requireScope signs a request in with a personal access token that has scope, or with a
+// session as CheckJWT does. Only routes registered with it take tokens, every other
+// route is behind CheckJWT, which refuses them, so a leaked token can't be used to manage
+// users, other tokens or the account it belongs to. The role of the token's user still
+// applies on top of its scopes.
+func (s *APIV1Service) requireScope(scope string) gin.HandlerFunc {
+	checkJWT := s.CheckJWT()
+	return func(c *gin.Context) {
+		token, err := getBearerToken(c)
+		if err != nil || !strings.HasPrefix(token, accessTokenPrefix) {
+			checkJWT(c)
+			return
+		}
+
+		if s.authenticateAccessToken(c, token, scope) {
+			c.Next()
+		}
+	}
+}
+
+// registerAccessTokenRoutes handles the personal access tokens of the current user,
+// protected by auth.
+func registerAccessTokenRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	tokens := rg.Group("tokens")
+	tokens.GET("", s.accessTokensHandler)
+	tokens.POST("", s.createAccessTokenHandler)
+	tokens.DELETE(":id", s.revokeAccessTokenHandler)
+}
+
+func (s *APIV1Service) accessTokensHandler(c *gin.Context) {
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	tokens, err := s.db.AccessTokens.GetAll(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// createAccessTokenHandler creates a personal access token for the current user. The
+// token is only shown in this response. Like adding a passkey it asks for the password,
+// so a stolen access token can't be turned into a long-lived one.
+func (s *APIV1Service) createAccessTokenHandler(c *gin.Context) {
+	var input struct {
+		Name          string   `json:"name" binding:"required,max=100"`
+		Scopes        []string `json:"scopes" binding:"required,min=1"`
+		ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+		Password      string   `json:"password" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	user, ok := s.currentUserWithPassword(c, input.Password)
+	if !ok {
+		return
+	}
+
+	scopes, err := accessTokenScopesFor(user.Role, input.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	days := input.ExpiresInDays
+	if days == 0 {
+		days = defaultAccessTokenDays
+	}
+
+	secret, err := pkg.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	plaintext := accessTokenPrefix + secret
+
+	token := &database.AccessToken{
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(input.Name),
+		Hint:      plaintext[:len(accessTokenPrefix)+accessTokenHintLength],
+		Scopes:    scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+	err = s.db.AccessTokens.Insert(c.Request.Context(), token, pkg.HashToken(plaintext))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserTokenCreate, database.AuditTargetUser, user.ID, map[string]any{"token_id": token.ID, "name": token.Name, "scopes": token.Scopes})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Token created! Copy it now, it won't be shown again.",
+		"token":        token,
+		"access_token": plaintext,
+	})
+}
+
+func (s *APIV1Service) revokeAccessTokenHandler(c *gin.Context) {
+	tokenID, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	err = s.db.AccessTokens.Delete(c.Request.Context(), int64(uid), int64(tokenID))
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserTokenRevoke, database.AuditTargetUser, int64(uid), map[string]any{"token_id": tokenID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully!"})
+}
+
+// authenticateAccessToken signs the request in with a personal access token that needs
+// scope, as CheckJWT does for access tokens. It writes the response and returns false if
+// the token is refused.
+func (s *APIV1Service) authenticateAccessToken(c *gin.Context, plaintext, scope string) bool {
+	token, err := s.db.AccessTokens.Authenticate(c.Request.Context(), pkg.HashToken(plaintext), c.ClientIP())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrTokenInvalidOrExpired})
+			return false
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	u, err := s.db.Users.GetByID(c.Request.Context(), token.UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthorized})
+		return false
+	}
+
+	if u.DeactivatedAt != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrAccountDeactivated})
+		return false
+	}
+
+	if !hasScope(token.Scopes, scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("This token lacks the %s scope.", scope)})
+		return false
+	}
+
+	// Pass user ID and role to handlers, like for a session.
+	c.Set("user_id", float64(u.ID))
+	c.Set("access_token_id", token.ID)
+	c.Set("role", s.userRole(c, u))
+	return true
+}
+
+// hasScope reports whether a token granted scopes may use scope.
+func hasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope || slices.Contains(impliedScopes[granted], scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessTokenScopesFor checks the scopes requested for a token of a user with the given
+// role, returning them sorted and without duplicates.
+func accessTokenScopesFor(role string, requested []string) ([]string, error) {
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		roles, ok := accessTokenScopes[scope]
+		if !ok {
+			known := make([]string, 0, len(accessTokenScopes))
+			for name := range accessTokenScopes {
+				known = append(known, name)
+			}
+			slices.Sort(known)
+			return nil, fmt.Errorf("unknown scope %q, use one of %s", scope, strings.Join(known, ", "))
+		}
+		if roles != nil && !slices.Contains(roles, role) {
+			return nil, fmt.Errorf("your role can't use the %s scope", scope)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
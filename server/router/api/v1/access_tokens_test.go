@@ -0,0 +1,224 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+const testAccessToken = accessTokenPrefix + "secret"
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		granted []string
+		scope   string
+		want    bool
+	}{
+		{granted: []string{scopePostsRead}, scope: scopePostsRead, want: true},
+		{granted: []string{scopePostsWrite}, scope: scopePostsRead, want: true},
+		{granted: []string{scopePostsPublish}, scope: scopePostsRead, want: true},
+		{granted: []string{scopePostsRead}, scope: scopePostsWrite},
+		{granted: []string{scopePostsWrite}, scope: scopePostsPublish},
+		{granted: []string{scopeTagsAdmin}, scope: scopePostsRead},
+		{granted: []string{scopeTagsAdmin, scopePostsWrite}, scope: scopePostsWrite, want: true},
+		{granted: nil, scope: scopePostsRead},
+	}
+
+	for _, tt := range tests {
+		if got := hasScope(tt.granted, tt.scope); got != tt.want {
+			t.Errorf("hasScope(%v, %s) = %v, want %v", tt.granted, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestAccessTokenScopesFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      string
+		requested []string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "sorted without duplicates",
+			role:      database.RoleAuthor,
+			requested: []string{scopePostsWrite, scopePostsRead, scopePostsWrite},
+			want:      []string{scopePostsRead, scopePostsWrite},
+		},
+		{
+			name:      "publisher scope for an editor",
+			role:      database.RoleEditor,
+			requested: []string{scopePostsPublish, scopeTagsAdmin},
+			want:      []string{scopePostsPublish, scopeTagsAdmin},
+		},
+		{name: "publisher scope for an author", role: database.RoleAuthor, requested: []string{scopePostsPublish}, wantErr: true},
+		{name: "unknown scope", role: database.RoleAdmin, requested: []string{"users:admin"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := accessTokenScopesFor(tt.role, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want an error: %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("scopes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// expectAccessToken expects the test token to be looked up and found with scopes.
+func expectAccessToken(t *testing.T, mock pgxmock.PgxPoolIface, scopes ...string) {
+	t.Helper()
+
+	now := time.Now()
+	ip := "203.0.113.7"
+	mock.ExpectQuery(`FROM personal_access_tokens`).WithArgs(pkg.HashToken(testAccessToken)).WillReturnRows(
+		pgxmock.NewRows([]string{
+			"id", "user_id", "name", "hint", "scopes", "expires_at", "last_used_at", "last_used_ip", "created_at",
+		}).AddRow(int64(2), int64(1), "ci", testAccessToken[:len(accessTokenPrefix)+accessTokenHintLength],
+			scopes, now.Add(time.Hour), &now, ip, now))
+	expectUser(t, mock, 1, "password")
+}
+
+func TestAuthenticateAccessToken(t *testing.T) {
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	header := http.Header{"Authorization": {"Bearer " + testAccessToken}}
+
+	tests := []struct {
+		name     string
+		handlers func(s *APIV1Service) []gin.HandlerFunc
+		expect   func(t *testing.T, mock pgxmock.PgxPoolIface)
+		status   int
+	}{
+		{
+			name: "granted scope",
+			handlers: func(s *APIV1Service) []gin.HandlerFunc {
+				return []gin.HandlerFunc{s.requireScope(scopePostsWrite), ok}
+			},
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) { expectAccessToken(t, mock, scopePostsWrite) },
+			status: http.StatusOK,
+		},
+		{
+			name: "implied scope",
+			handlers: func(s *APIV1Service) []gin.HandlerFunc {
+				return []gin.HandlerFunc{s.requireScope(scopePostsRead), ok}
+			},
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) { expectAccessToken(t, mock, scopePostsWrite) },
+			status: http.StatusOK,
+		},
+		{
+			name: "missing scope",
+			handlers: func(s *APIV1Service) []gin.HandlerFunc {
+				return []gin.HandlerFunc{s.requireScope(scopePostsWrite), ok}
+			},
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) { expectAccessToken(t, mock, scopePostsRead) },
+			status: http.StatusForbidden,
+		},
+		{
+			// Not even looked up.
+			name:     "route without a scope",
+			handlers: func(s *APIV1Service) []gin.HandlerFunc { return []gin.HandlerFunc{s.CheckJWT(), ok} },
+			expect:   func(t *testing.T, mock pgxmock.PgxPoolIface) {},
+			status:   http.StatusForbidden,
+		},
+		{
+			name: "unknown or expired token",
+			handlers: func(s *APIV1Service) []gin.HandlerFunc {
+				return []gin.HandlerFunc{s.requireScope(scopePostsRead), ok}
+			},
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(`FROM personal_access_tokens`).WithArgs(pkg.HashToken(testAccessToken)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}))
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			tt.expect(t, mock)
+
+			w := serve(t, http.MethodGet, "/posts", "/posts", nil, header, tt.handlers(s)...)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+// Sessions aren't limited by scopes.
+func TestRequireScopeSession(t *testing.T) {
+	s, mock := newTestService(t)
+	expectSignedIn(mock, 1, 5, database.RoleAuthor, false)
+
+	header := http.Header{"Authorization": {"Bearer " + accessJWT(t, s, 1, 5)}}
+	w := serve(t, http.MethodGet, "/posts", "/posts", nil, header,
+		s.requireScope(scopeTagsAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+// Only the routes registered with requireScope take tokens.
+func TestAccessTokenRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		expect func(t *testing.T, mock pgxmock.PgxPoolIface)
+		err    string
+	}{
+		{
+			name:   "post route",
+			method: http.MethodGet,
+			target: "/api/v1/auth/posts",
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) { expectAccessToken(t, mock, scopeTagsAdmin) },
+			err:    "This token lacks the posts:read scope.",
+		},
+		{
+			name:   "revisions of a post",
+			method: http.MethodGet,
+			target: "/api/v1/auth/posts/1/revisions",
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {},
+			err:    "Personal access tokens can't be used for this endpoint.",
+		},
+		{
+			name:   "token route",
+			method: http.MethodPost,
+			target: "/api/v1/auth/tokens",
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {},
+			err:    "Personal access tokens can't be used for this endpoint.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			tt.expect(t, mock)
+
+			r := gin.New()
+			registerAuthRoutes(r.Group("/api/v1"), s)
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.RemoteAddr = "203.0.113.7:1234"
+			req.Header.Set("Authorization", "Bearer "+testAccessToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden || decode(t, w)["error"] != tt.err {
+				t.Errorf("got %d %s, want %d with %q", w.Code, w.Body, http.StatusForbidden, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// registerAuditRoutes handles the audit log, admins only.
+func registerAuditRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	rg.GET("audit-logs", s.RequireRole(database.RoleAdmin), s.auditLogsHandler)
+}
+
+func (s *APIV1Service) auditLogsHandler(c *gin.Context) {
+	limit, offset := getPagination(c)
+
+	entries, total, err := s.db.AuditLogs.GetAll(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "audit_logs": entries})
+}
+
+// audit records an action of the current user in the audit log. Failures are only
+// logged, they must not undo the action itself.
+func (s *APIV1Service) audit(c *gin.Context, action, targetType string, targetID int64, details map[string]any) {
+	entry := &database.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   &targetID,
+		Details:    details,
+		IP:         c.ClientIP(),
+	}
+	if uid := int64(c.GetFloat64("user_id")); uid != 0 {
+		entry.UserID = &uid
+	}
+
+	err := s.db.AuditLogs.Insert(c.Request.Context(), entry)
+	if err != nil {
+		s.logger.Error("failed to write audit log", "action", action, "error", err)
+	}
+}
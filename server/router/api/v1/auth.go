@@ -1,28 +1,52 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
 )
 
 // registerAuthRoutes registers the routes related to authentication.
 func registerAuthRoutes(rg *gin.RouterGroup, s *APIV1Service) {
 	auth := rg.Group("auth")
 	auth.POST("login", s.loginHandler)
+	auth.POST("login/2fa", s.loginTwoFactorHandler)
 	auth.POST("refresh", s.refreshTokenHandler)
+	auth.POST("logout", s.logoutHandler)
+	auth.POST("forgot-password", s.forgotPasswordHandler)
+	auth.POST("reset-password", s.resetPasswordHandler)
+	registerWebAuthnRoutes(auth, s)
+
+	// Routes open to personal access tokens sign requests in with requireScope.
+	registerPostRoutes(auth, s)
+	registerTagAdminRoutes(auth, s)
 
 	auth.Use(s.CheckJWT())
 	auth.GET("status", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "OK"})
 	})
-	auth.GET("login-attempts", s.handleLoginAttemptsViewer)
-
-	// this route is only being used to securely manage the posts.
-	registerPostRoutes(auth, s)
+	auth.GET("me", s.meHandler)
+	auth.PATCH("me", s.updateMeHandler)
+	auth.PUT("me/password", s.updateMyPasswordHandler)
+	auth.POST("logout-all", s.logoutAllHandler)
+	registerSessionRoutes(auth, s)
+	registerAccessTokenRoutes(auth, s)
+	registerTwoFactorRoutes(auth, s)
+	registerWebAuthnAdminRoutes(auth, s)
+	auth.GET("login-attempts", s.RequireRole(database.RoleAdmin), s.handleLoginAttemptsViewer)
+
+	registerSeriesAdminRoutes(auth, s)
+	registerTrashRoutes(auth, s)
+	registerUserRoutes(auth, s)
+	registerInvitationAdminRoutes(auth, s)
+	registerAuditRoutes(auth, s)
 }
 
 func (s *APIV1Service) loginHandler(c *gin.Context) {
@@ -42,7 +66,71 @@ func (s *APIV1Service) loginHandler(c *gin.Context) {
 		return
 	}
 
-	userAttempts, err := s.db.Users.GetLoginAttempt(c.Request.Context(), user.ID, c.ClientIP())
+	attemptID, ok := s.checkLoginAttempt(c, user.ID)
+	if !ok {
+		return
+	}
+
+	match, err := user.Password.Match(input.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !match {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect password"})
+		return
+	}
+
+	if user.DeactivatedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDeactivated})
+		return
+	}
+
+	// The password alone isn't enough, hand out a challenge token for the second step.
+	// The attempt counter isn't reset yet, so guessing codes counts as failed logins.
+	if user.TwoFactorEnabled {
+		mfaToken, err := generateMFAToken(c.Request.Context(), user.ID, s)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Enter the code from your authenticator app.",
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+		})
+		return
+	}
+
+	s.completeLogin(c, user.ID, attemptID)
+}
+
+// completeLogin issues the tokens of a user who passed every sign in step and clears
+// their failed attempts.
+func (s *APIV1Service) completeLogin(c *gin.Context, userID, attemptID int64) {
+	accessToken, refreshToken, err := generateTokens(c, userID, s)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Successful login, reset all login_attempts restriction.
+	err = s.db.Users.ResetAllLoginAttempt(c.Request.Context(), userID, attemptID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.respondWithTokens(c, http.StatusOK, gin.H{"message": "Login successful!"}, accessToken, refreshToken)
+}
+
+// checkLoginAttempt counts a sign in attempt for userID from the client IP and bans the
+// IP after too many of them. It writes the response and returns false if the attempt
+// must be refused.
+func (s *APIV1Service) checkLoginAttempt(c *gin.Context, userID int64) (attemptID int64, ok bool) {
+	userAttempts, err := s.db.Users.GetLoginAttempt(c.Request.Context(), userID, c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -50,7 +138,6 @@ func (s *APIV1Service) loginHandler(c *gin.Context) {
 
 	var (
 		currentAttempt int64 = 1
-		attemptID      int64
 		bannedUntil    time.Time
 	)
 
@@ -101,7 +188,7 @@ func (s *APIV1Service) loginHandler(c *gin.Context) {
 
 	} else {
 		// No record exists, so create one.
-		aid, err := s.db.Users.LogAttempt(c.Request.Context(), user.ID, c.ClientIP(), currentAttempt)
+		aid, err := s.db.Users.LogAttempt(c.Request.Context(), userID, c.ClientIP(), currentAttempt)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -109,74 +196,149 @@ func (s *APIV1Service) loginHandler(c *gin.Context) {
 		attemptID = aid
 	}
 
-	match, err := user.Password.Match(input.Password)
+	return attemptID, true
+}
+
+// refreshTokenHandler exchanges a refresh token for a new pair of tokens. The refresh
+// token is rotated: the old one stops working, and using it again revokes its session.
+func (s *APIV1Service) refreshTokenHandler(c *gin.Context) {
+	uid, tokenID, ok := s.refreshTokenClaims(c)
+	if !ok {
+		return
+	}
+
+	u, err := s.db.Users.GetByID(c.Request.Context(), uid)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthorized})
+		return
+	}
+	if u.DeactivatedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDeactivated})
 		return
 	}
 
-	if !match {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect password"})
+	nextTokenID, err := pkg.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	accessToken, refreshToken, err := generateTokens(user.ID, s)
+	next := &database.RefreshToken{
+		ExpiresAt: time.Now().Add(time.Hour * time.Duration(s.config.JWT.RefExp)),
+	}
+	err = s.db.RefreshTokens.Rotate(c.Request.Context(), pkg.HashToken(tokenID), pkg.HashToken(nextTokenID), next)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, database.ErrRefreshTokenReused):
+			s.logger.Warn("refresh token reused, revoked its session", "user_id", uid, "ip", c.ClientIP())
+			s.audit(c, database.AuditUserTokenReuse, database.AuditTargetUser, uid, nil)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrTokenInvalidOrExpired})
+		case errors.Is(err, database.ErrRecordNotFound):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrTokenInvalidOrExpired})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	// Successful login, reset all login_attempts restriction.
-	err = s.db.Users.ResetAllLoginAttempt(c.Request.Context(), user.ID, attemptID)
+	accessToken, refreshToken, err := signTokens(c.Request.Context(), next.UserID, next.SessionID, nextTokenID, next.ExpiresAt, s)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":       "Login successful!",
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	s.respondWithTokens(c, http.StatusOK, gin.H{"message": "Token has been refreshed!"}, accessToken, refreshToken)
 }
 
-func (s *APIV1Service) refreshTokenHandler(c *gin.Context) {
-	token, err := getBearerToken(c)
+// logoutHandler signs out the session of the refresh token in the Authorization header,
+// or in the refresh cookie in cookie mode. The cookies are only cleared once the session
+// was revoked, so another site can't sign the browser out without the CSRF token.
+func (s *APIV1Service) logoutHandler(c *gin.Context) {
+	_, tokenID, ok := s.refreshTokenClaims(c)
+	if !ok {
+		return
+	}
+
+	// An unknown or already revoked token is signed out as well.
+	err := s.db.Sessions.RevokeByToken(c.Request.Context(), pkg.HashToken(tokenID))
+	if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out successfully!"})
+}
+
+// logoutAllHandler ends every session of the current user, this one included, and
+// revokes their personal access tokens.
+func (s *APIV1Service) logoutAllHandler(c *gin.Context) {
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	err := s.db.Sessions.RevokeAll(c.Request.Context(), int64(uid), 0)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	claims, err := parseJWT(token, s.config.JWT.RefSecret)
+	revoked, err := s.db.AccessTokens.DeleteAll(c.Request.Context(), int64(uid))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	s.audit(c, database.AuditUserSignOutAll, database.AuditTargetUser, int64(uid), map[string]any{"access_tokens_revoked": revoked})
+
+	s.clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out of all sessions!"})
+}
+
+// refreshTokenClaims verifies the refresh token in the Authorization header, or in the
+// refresh cookie in cookie mode, and returns the user and token IDs it carries. It writes
+// the response and returns false if the token is missing or invalid.
+func (s *APIV1Service) refreshTokenClaims(c *gin.Context) (userID int64, tokenID string, ok bool) {
+	token, fromCookie, err := s.requestToken(c, refreshCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, "", false
+	}
+
+	if fromCookie && !validCSRF(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrInvalidCSRFToken})
+		return 0, "", false
+	}
+
+	claims, err := s.parseJWT(c.Request.Context(), token, s.config.JWT.RefSecret)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return 0, "", false
+	}
+
 	// Check if the token has a "type" claim and if it's a "refresh" token.
 	tokenType, ok := claims["type"].(string)
 	if !ok || tokenType != TokenTypeRefresh {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidFormat})
-		return
+		return 0, "", false
 	}
 
 	uid, ok := claims["user_id"].(float64)
 	if !ok || uid == 0 {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthorized})
-		return
+		return 0, "", false
 	}
 
-	accessToken, refreshToken, err := generateTokens(int64(uid), s)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	// Refresh tokens issued before they were stored server side carry no ID.
+	tokenID, ok = claims["jti"].(string)
+	if !ok || tokenID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrTokenInvalidOrExpired})
+		return 0, "", false
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":       "Token has been refreshed!",
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	return int64(uid), tokenID, true
 }
 
 // handleLoginAttemptsViewer handle login attempts data.
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// refreshJWT signs a refresh token with ID tokenID for session sid of userID.
+func refreshJWT(t *testing.T, s *APIV1Service, userID, sid int64, tokenID string) string {
+	t.Helper()
+
+	_, token, err := signTokens(context.Background(), userID, sid, tokenID, time.Now().Add(time.Hour), s)
+	if err != nil {
+		t.Fatalf("signTokens: %v", err)
+	}
+	return token
+}
+
+// expectRefreshToken expects Rotate to look up the refresh token with ID tokenID.
+func expectRefreshToken(mock pgxmock.PgxPoolIface, tokenID string, rotatedAt, revokedAt *time.Time) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM refresh_tokens rt`).WithArgs(pkg.HashToken(tokenID)).WillReturnRows(
+		pgxmock.NewRows([]string{"id", "user_id", "session_id", "expires_at", "rotated_at", "revoked_at"}).
+			AddRow(int64(3), int64(1), int64(5), time.Now().Add(time.Hour), rotatedAt, revokedAt))
+}
+
+func TestRefreshToken(t *testing.T) {
+	refresh := func(t *testing.T, s *APIV1Service, token string) (int, map[string]any) {
+		t.Helper()
+		header := http.Header{"Authorization": {"Bearer " + token}}
+		w := serve(t, http.MethodPost, "/auth/refresh", "/auth/refresh", nil, header, s.refreshTokenHandler)
+		return w.Code, decode(t, w)
+	}
+	used := time.Now().Add(-time.Minute)
+
+	t.Run("rotates the token", func(t *testing.T) {
+		s, mock := newTestService(t)
+		expectUser(t, mock, 1, "password")
+		expectRefreshToken(mock, "old-token", nil, nil)
+		mock.ExpectExec(`UPDATE refresh_tokens SET rotated_at`).WithArgs(int64(3)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectQuery(`INSERT INTO refresh_tokens`).
+			WithArgs(int64(1), int64(5), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(4), time.Now()))
+		mock.ExpectExec(`UPDATE sessions SET expires_at`).WithArgs(pgxmock.AnyArg(), int64(5)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+
+		status, body := refresh(t, s, refreshJWT(t, s, 1, 5, "old-token"))
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %v", status, http.StatusOK, body)
+		}
+
+		next, _ := body["refresh_token"].(string)
+		claims, err := s.parseJWT(context.Background(), next, s.config.JWT.RefSecret)
+		if err != nil {
+			t.Fatalf("parse new refresh token: %v", err)
+		}
+		if claims["jti"] == "old-token" || claims["sid"] != float64(5) {
+			t.Errorf("new refresh token claims = %v, want a new ID in session 5", claims)
+		}
+	})
+
+	t.Run("reused token revokes the session", func(t *testing.T) {
+		s, mock := newTestService(t)
+		expectUser(t, mock, 1, "password")
+		expectRefreshToken(mock, "old-token", &used, nil)
+		mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(int64(5)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+		expectAudit(mock, database.AuditUserTokenReuse)
+
+		if status, body := refresh(t, s, refreshJWT(t, s, 1, 5, "old-token")); status != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d: %v", status, http.StatusUnauthorized, body)
+		}
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		s, mock := newTestService(t)
+		expectUser(t, mock, 1, "password")
+		expectRefreshToken(mock, "old-token", nil, &used)
+		mock.ExpectRollback()
+
+		if status, body := refresh(t, s, refreshJWT(t, s, 1, 5, "old-token")); status != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d: %v", status, http.StatusUnauthorized, body)
+		}
+	})
+
+	t.Run("access token", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		if status, body := refresh(t, s, accessJWT(t, s, 1, 5)); status != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d: %v", status, http.StatusUnauthorized, body)
+		}
+	})
+}
+
+func TestLogoutAll(t *testing.T) {
+	s, mock := newTestService(t)
+	mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(int64(1), int64(0)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectExec(`DELETE FROM personal_access_tokens`).WithArgs(int64(1)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	expectAudit(mock, database.AuditUserSignOutAll)
+
+	w := serve(t, http.MethodPost, "/auth/logout-all", "/auth/logout-all", nil, nil, signedIn(1), s.logoutAllHandler)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
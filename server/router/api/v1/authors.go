@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	cache "github.com/chenyahui/gin-cache"
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// registerAuthorRoutes handles public author profiles and their feeds.
+func registerAuthorRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	authors := rg.Group("authors")
+	authors.GET(":handle", cache.CacheByRequestURI(s.redisStore, 30*time.Minute), s.authorHandler)
+	authors.GET(":handle/rss.xml", cache.CacheByRequestURI(s.redisStore, 30*time.Minute), s.authorFeedHandler(false))
+	authors.GET(":handle/atom.xml", cache.CacheByRequestURI(s.redisStore, 30*time.Minute), s.authorFeedHandler(true))
+}
+
+// authorHandler returns an author profile along with a page of their published posts.
+func (s *APIV1Service) authorHandler(c *gin.Context) {
+	author, err := s.db.Users.GetProfile(c.Request.Context(), c.Param("handle"))
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset := getPagination(c)
+	filter := database.Filter{
+		Limit:       limit,
+		Offset:      offset,
+		OrderBy:     "published_at",
+		Sort:        "DESC",
+		IsPublished: true,
+		AuthorID:    author.ID,
+	}
+
+	posts, totalPost, err := s.db.Posts.GetAll(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, post := range posts {
+		redactProtected(post)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"author": author, "total_post": totalPost, "posts": posts})
+}
+
+// authorFeedHandler serves the latest published posts of an author as RSS, or as Atom if atom is set.
+func (s *APIV1Service) authorFeedHandler(atom bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		author, err := s.db.Users.GetProfile(c.Request.Context(), c.Param("handle"))
+		if err != nil {
+			c.String(authorErrorStatus(err), err.Error())
+			return
+		}
+
+		filter := database.Filter{
+			Limit:       100,
+			Offset:      0,
+			OrderBy:     "published_at",
+			Sort:        "DESC",
+			IsPublished: true,
+			AuthorID:    author.ID,
+		}
+
+		posts, _, err := s.db.Posts.GetAll(c.Request.Context(), filter)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		title := fmt.Sprintf("%s - %s", s.config.Blog.Name, author.Name)
+		link := fmt.Sprintf("%s/authors/%s", s.config.Blog.URL, url.PathEscape(author.Handle))
+		writeFeed(c, s.newFeed(title, link, posts), atom)
+	}
+}
+
+// meHandler returns the account of the current user, including the profile.
+func (s *APIV1Service) meHandler(c *gin.Context) {
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	user, err := s.db.Users.GetByID(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// updateMeHandler updates the profile of the current user. Fields left out are kept.
+func (s *APIV1Service) updateMeHandler(c *gin.Context) {
+	type link struct {
+		Name string `json:"name" binding:"required,max=64"`
+		URL  string `json:"url" binding:"required,url"`
+	}
+	var input struct {
+		Name      *string `json:"name" binding:"omitempty,min=1,max=255"`
+		Handle    *string `json:"handle" binding:"omitempty,min=1,max=64"`
+		Bio       *string `json:"bio" binding:"omitempty,max=2000"`
+		AvatarURL *string `json:"avatar_url" binding:"omitempty,max=2048"`
+		Links     []link  `json:"links" binding:"omitempty,max=10,dive"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	user, err := s.db.Users.GetByID(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil {
+		user.Name = *input.Name
+	}
+	if input.Handle != nil {
+		// Handles end up in URLs, only take them if they're already slugs.
+		if !database.ValidHandle(*input.Handle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "handle may only contain lowercase letters, digits and dashes"})
+			return
+		}
+		user.Handle = *input.Handle
+	}
+	if input.Bio != nil {
+		user.Bio = *input.Bio
+	}
+	if input.AvatarURL != nil {
+		if *input.AvatarURL != "" && !isHTTPURL(*input.AvatarURL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "avatar_url must be an http or https URL"})
+			return
+		}
+		user.AvatarURL = *input.AvatarURL
+	}
+	// An empty list removes all links, leaving it out keeps them.
+	if input.Links != nil {
+		user.Links = make([]database.Link, len(input.Links))
+		for i, l := range input.Links {
+			user.Links[i] = database.Link{Name: l.Name, URL: l.URL}
+		}
+	}
+
+	err = s.db.Users.UpdateProfile(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Bylines and author pages are cached.
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully!", "user": user})
+}
+
+// isHTTPURL reports whether raw is an absolute http or https URL.
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// authorErrorStatus maps errors from the users model to HTTP status codes.
+func authorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, database.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrDuplicateHandle), errors.Is(err, database.ErrDuplicateEmail):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
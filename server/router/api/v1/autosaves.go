@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// registerAutosaveRoutes handles the autosave buffers of posts, protected by auth.
+// Buffers belong to the logged in user and never change the live post until applied.
+func registerAutosaveRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	autosave := rg.Group(":id/autosave", s.CanEditPost())
+	autosave.GET("", s.getAutosaveHandler)
+	autosave.PUT("", s.saveAutosaveHandler)
+	autosave.DELETE("", s.discardAutosaveHandler)
+	autosave.POST("apply", s.applyAutosaveHandler)
+}
+
+func (s *APIV1Service) getAutosaveHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	autosave, err := s.db.Autosaves.Get(c.Request.Context(), pid, int64(uid))
+	if err != nil {
+		c.JSON(autosaveErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"autosave": autosave})
+}
+
+// saveAutosaveHandler stores work in progress. Fields aren't validated here,
+// half written posts are fine until the buffer is applied.
+func (s *APIV1Service) saveAutosaveHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		Title       string   `json:"title" binding:"max=255"`
+		Description *string  `json:"description" binding:"omitempty,max=255"`
+		Content     string   `json:"content"`
+		Slug        string   `json:"slug" binding:"max=255"`
+		Tags        []string `json:"tags"` // Absent keeps the tags of the post when applied
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	// Trashed posts can't be edited.
+	_, err = s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(autosaveErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	autosave := &database.Autosave{
+		PostID:      pid,
+		UserID:      int64(uid),
+		Title:       input.Title,
+		Description: input.Description,
+		Content:     input.Content,
+		Slug:        input.Slug,
+		Tags:        input.Tags,
+	}
+
+	err = s.db.Autosaves.Save(c.Request.Context(), autosave)
+	if err != nil {
+		c.JSON(autosaveErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Autosaved", "autosave": autosave})
+}
+
+func (s *APIV1Service) discardAutosaveHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	err = s.db.Autosaves.Delete(c.Request.Context(), pid, int64(uid))
+	if err != nil {
+		c.JSON(autosaveErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Autosave discarded"})
+}
+
+// applyAutosaveHandler promotes the autosave buffer to the live post, recording a
+// revision like a regular update, and removes the buffer. Like PATCH, it honours If-Match
+// and leaves the tags alone unless the buffer has some, even an empty list.
+func (s *APIV1Service) applyAutosaveHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	autosave, err := s.db.Autosaves.Get(c.Request.Context(), pid, int64(uid))
+	if err != nil {
+		c.JSON(autosaveErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if autosave.Title == "" || autosave.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A post needs a title and content before it can be saved."})
+		return
+	}
+
+	postSlug, err := makeSlug(autosave.Slug, autosave.Title)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	taken, err := s.db.Posts.Exists(c.Request.Context(), postSlug, pid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if taken {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A post with this slug already exists. Please choose a unique slug."})
+		return
+	}
+
+	post := &database.Post{
+		ID:          pid,
+		Title:       autosave.Title,
+		Description: autosave.Description,
+		Content:     autosave.Content,
+		Slug:        postSlug,
+		Tags:        autosave.Tags,
+	}
+
+	version, precondition := ifMatchVersion(c)
+	if precondition {
+		post.Version = version
+	}
+
+	err = s.db.Posts.Update(c.Request.Context(), post, int64(uid), false)
+	if err != nil {
+		c.JSON(updateErrorStatus(err, precondition), gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Autosaves.Delete(c.Request.Context(), pid, int64(uid))
+	if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// delete all other cache key
+	deleteCacheKey(s.redisStore)
+
+	updatedPost, err := s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", etag(updatedPost.Version))
+	c.JSON(http.StatusOK, gin.H{"message": "Autosave applied successfully!", "post": updatedPost})
+}
+
+// autosaveErrorStatus maps an autosave error to an HTTP status code.
+func autosaveErrorStatus(err error) int {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
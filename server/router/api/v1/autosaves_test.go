@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+func TestApplyAutosaveTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		wantTags bool // Whether the tags of the post get replaced
+	}{
+		{name: "tags untouched", tags: nil},
+		{name: "tags cleared", tags: []string{}, wantTags: true},
+		{name: "tags edited", tags: []string{"go"}, wantTags: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			now := time.Now()
+
+			mock.ExpectQuery(`FROM post_autosaves`).WithArgs(5, int64(1)).WillReturnRows(
+				pgxmock.NewRows([]string{"post_id", "user_id", "title", "description", "content", "slug", "tags", "created_at", "updated_at"}).
+					AddRow(5, int64(1), "Title", nil, "Content", "title", tt.tags, now, now))
+			mock.ExpectQuery(`SELECT EXISTS`).WithArgs("title", 5).
+				WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT version FROM blog_posts`).WithArgs(5).
+				WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(1))
+			mock.ExpectQuery(`SELECT slug FROM blog_posts`).WithArgs(5).
+				WillReturnRows(pgxmock.NewRows([]string{"slug"}).AddRow("title"))
+			mock.ExpectExec(`INSERT INTO post_revisions`).WithArgs(5).
+				WillReturnResult(pgxmock.NewResult("INSERT", 0))
+			mock.ExpectQuery(`UPDATE blog_posts`).WithArgs("Title", pgxmock.AnyArg(), "Content", "title", 5).
+				WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(2))
+			if tt.wantTags {
+				mock.ExpectExec(`DELETE FROM blog_tag`).WithArgs(5).
+					WillReturnResult(pgxmock.NewResult("DELETE", 2))
+				for _, tag := range tt.tags {
+					mock.ExpectQuery(`SELECT id FROM tags`).WithArgs(tag).
+						WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(9))
+					mock.ExpectExec(`INSERT INTO blog_tag`).WithArgs(5, 9).
+						WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				}
+			}
+			mock.ExpectExec(`INSERT INTO post_revisions`).WithArgs(5, int64(1)).
+				WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			mock.ExpectCommit()
+
+			mock.ExpectExec(`DELETE FROM post_autosaves`).WithArgs(5, int64(1)).
+				WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			expectPost(mock, &database.Post{ID: 5, Title: "Title", Content: "Content", Slug: "title", Version: 2})
+
+			w := serve(t, http.MethodPost, "/posts/:id/autosave/apply", "/posts/5/autosave/apply", nil, nil,
+				signedIn(1), s.applyAutosaveHandler)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -21,7 +22,8 @@ func registerBlogRoutes(rg *gin.RouterGroup, s *APIV1Service) {
 	posts := rg.Group("posts")
 	posts.GET("", cache.CacheByRequestURI(s.redisStore, 10*time.Minute), s.blogPostsHandler)
 	posts.GET("search", s.searchPostsHandler)
-	posts.GET(":slug", cache.CacheByRequestURI(s.redisStore, 30*time.Minute), s.getBlogPostBySlugHandler)
+	posts.GET(":slug", cache.CacheByRequestURI(s.redisStore, 30*time.Minute, cache.WithCacheStrategyByRequest(cacheUnlessUnlocking)), s.getBlogPostBySlugHandler)
+	posts.POST(":slug/unlock", s.unlockPostHandler)
 	posts.GET("top-posts", cache.CacheByRequestURI(s.redisStore, 30*time.Minute), s.topPostsHandler)
 	posts.GET("tags", cache.CacheByRequestURI(s.redisStore, 30*time.Minute), s.blogTagsHandler)
 
@@ -32,23 +34,17 @@ func registerBlogRoutes(rg *gin.RouterGroup, s *APIV1Service) {
 
 // blogPostsHandler display posts.
 func (s *APIV1Service) blogPostsHandler(c *gin.Context) {
-	posts, _, totalPost, err := getPosts(c, s)
+	posts, _, totalPost, err := getPosts(c, s, false)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// To prevent any potential misuse, we filter out unpublished posts here and ignore them.
-	var filteredPosts []*database.Post
 	for _, post := range posts {
-		// ignore un-published posts
-		if !post.IsPublished {
-			continue
-		}
-		filteredPosts = append(filteredPosts, post)
+		redactProtected(post)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"total_post": totalPost, "posts": filteredPosts})
+	c.JSON(http.StatusOK, gin.H{"total_post": totalPost, "posts": posts})
 }
 
 func (s *APIV1Service) topPostsHandler(c *gin.Context) {
@@ -62,8 +58,8 @@ func (s *APIV1Service) topPostsHandler(c *gin.Context) {
 }
 
 func (s *APIV1Service) blogTagsHandler(c *gin.Context) {
-	// Fetch all tags from the database
-	tags, err := s.db.Tags.GetAll(c.Request.Context())
+	// Fetch the tags of published posts from the database
+	tags, err := s.db.Tags.GetAll(c.Request.Context(), true)
 	if err != nil {
 		// Handle database error
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -85,10 +81,28 @@ func (s *APIV1Service) getBlogPostBySlugHandler(c *gin.Context) {
 	// Fetch the post from the database
 	post, err := s.db.Posts.GetBySlug(c.Request.Context(), slug)
 	if err != nil {
+		// Point retired slugs at the post's current slug.
+		if errors.Is(err, database.ErrRecordNotFound) {
+			canonical, rerr := s.db.Posts.GetRedirectSlug(c.Request.Context(), slug)
+			if rerr == nil {
+				c.Header("Location", "/api/v1/posts/"+canonical)
+				c.JSON(http.StatusMovedPermanently, gin.H{"message": "Post has moved", "slug": canonical})
+				return
+			}
+		}
 		// Handle database error (e.g., post not found)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Protected posts only show their metadata until they're unlocked.
+	locked := post.IsProtected && !s.postUnlocked(c, post)
+	if locked {
+		redactProtected(post)
+	} else if post.IsProtected {
+		// Keep unlocked content out of shared caches.
+		c.Header("Cache-Control", "private, no-store")
+	}
+
 	var (
 		previousPost, nextPost *database.Post
 	)
@@ -109,6 +123,7 @@ func (s *APIV1Service) getBlogPostBySlugHandler(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		redactProtected(previousPost)
 	}
 
 	// Fetch the next post ID if exists
@@ -127,7 +142,16 @@ func (s *APIV1Service) getBlogPostBySlugHandler(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		redactProtected(nextPost)
+	}
+
+	// Fetch the series the post belongs to, if any
+	series, err := s.db.Series.GetByPost(c.Request.Context(), post.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+
 	// Update Post views
 	err = s.db.Posts.UpdateViews(c.Request.Context(), post.ID)
 	if err != nil {
@@ -135,14 +159,20 @@ func (s *APIV1Service) getBlogPostBySlugHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"post": post, "previous_post": previousPost, "next_post": nextPost})
+	c.JSON(http.StatusOK, gin.H{
+		"post":          post,
+		"previous_post": previousPost,
+		"next_post":     nextPost,
+		"series":        series,
+		"locked":        locked,
+	})
 }
 
 func (s *APIV1Service) rssHandler(c *gin.Context) {
 	filter := database.Filter{
 		Limit:       100,
 		Offset:      0,
-		OrderBy:     "created_at",
+		OrderBy:     "published_at",
 		Sort:        "DESC",
 		IsPublished: true,
 	}
@@ -153,33 +183,56 @@ func (s *APIV1Service) rssHandler(c *gin.Context) {
 		return
 	}
 
+	writeFeed(c, s.newFeed(s.config.Blog.Name, s.config.Blog.URL, posts), false)
+}
+
+// newFeed builds a feed with one item per post.
+func (s *APIV1Service) newFeed(title, link string, posts []*database.Post) *feeds.Feed {
 	feed := &feeds.Feed{
-		Title:   s.config.Blog.Name,
+		Title:   title,
 		Created: time.Now(),
-		Link:    &feeds.Link{Href: s.config.Blog.URL},
+		Link:    &feeds.Link{Href: link},
 	}
 
 	var items []*feeds.Item
 	for _, post := range posts {
+		description := MarkdownToHTML(post.Content)
+		if post.IsProtected {
+			description = protectedContent
+		}
 		item := &feeds.Item{
 			Id:          post.Slug,
 			Title:       post.Title,
 			Link:        &feeds.Link{Href: fmt.Sprintf("%s/posts/%s", s.config.Blog.URL, post.Slug)},
-			Description: MarkdownToHTML(post.Content),
+			Description: description,
+			Author:      &feeds.Author{Name: authorNames(post)},
 			Created:     post.CreatedAt,
 		}
+		if post.PublishedAt != nil {
+			item.Created = *post.PublishedAt
+		}
 		items = append(items, item)
 	}
 
 	feed.Items = items
-	rss, err := feed.ToRss()
+	return feed
+}
+
+// writeFeed writes feed as RSS, or as Atom if atom is set.
+func writeFeed(c *gin.Context, feed *feeds.Feed, atom bool) {
+	out, err := feed.ToRss()
+	contentType := "application/xml"
+	if atom {
+		out, err = feed.ToAtom()
+		contentType = "application/atom+xml"
+	}
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
-	c.Writer.Header().Add("Content-Type", "application/xml")
+	c.Writer.Header().Add("Content-Type", contentType)
 
-	_, err = c.Writer.WriteString(rss)
+	_, err = c.Writer.WriteString(out)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -235,7 +288,7 @@ func (s *APIV1Service) siteMapHandler(c *gin.Context) {
 		filter := database.Filter{
 			Limit:       batchSize,
 			Offset:      offset,
-			OrderBy:     "created_at",
+			OrderBy:     "published_at",
 			Sort:        "ASC",
 			IsPublished: true,
 		}
@@ -254,7 +307,7 @@ func (s *APIV1Service) siteMapHandler(c *gin.Context) {
 		offset += batchSize
 	}
 
-	tags, err := s.db.Tags.GetAll(c.Request.Context())
+	tags, err := s.db.Tags.GetAll(c.Request.Context(), true)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -272,8 +325,8 @@ func (s *APIV1Service) siteMapHandler(c *gin.Context) {
 		{"/archives"},
 		{"/tags"},
 		{"/about"},
-		{"?limit=10&offset=0&order_by=created_at&sort=DESC"},
-		{"?limit=10&offset=0&order_by=created_at&sort=ASC"},
+		{"?limit=10&offset=0&order_by=published_at&sort=DESC"},
+		{"?limit=10&offset=0&order_by=published_at&sort=ASC"},
 		{"?limit=10&offset=0&order_by=title&sort=ASC"},
 	}
 
@@ -302,6 +355,20 @@ func (s *APIV1Service) siteMapHandler(c *gin.Context) {
 		})
 	}
 
+	// Add author URLs
+	authors, err := s.db.Users.GetAuthors(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, author := range authors {
+		urls = append(urls, URL{
+			Loc:     fmt.Sprintf("%s/authors/%s", siteURL, url.PathEscape(author.Handle)),
+			LastMod: now,
+		})
+	}
+
 	// Add archive by year URLs
 	lists, err := s.db.Posts.YearlyStatsList(c.Request.Context())
 	if err != nil {
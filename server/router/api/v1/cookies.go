@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// authModeCookie keeps the tokens of the dashboard in HttpOnly cookies instead of handing
+// them to the browser's scripts.
+const authModeCookie = "cookie"
+
+// Cookies of the cookie auth mode.
+const (
+	accessCookie  = "blog_access"  // Access token, HttpOnly
+	refreshCookie = "blog_refresh" // Refresh token, HttpOnly
+	csrfCookie    = "blog_csrf"    // CSRF token, readable by the dashboard
+)
+
+const (
+	// authCookiePath limits the token cookies to the routes that use them.
+	authCookiePath = "/api/v1/auth"
+
+	// csrfHeader carries the CSRF token on state changing requests in cookie mode.
+	csrfHeader = "X-CSRF-Token"
+)
+
+// ErrInvalidCSRFToken is returned when a request signed in with cookies lacks the CSRF token.
+const ErrInvalidCSRFToken = "Missing or invalid CSRF token."
+
+// cookieMode reports whether the request gets and sends its tokens as cookies. Requests
+// with an Authorization header keep using bearer tokens, so scripts work in either mode.
+func (s *APIV1Service) cookieMode(c *gin.Context) bool {
+	return s.config.Auth.Mode == authModeCookie && c.GetHeader("Authorization") == ""
+}
+
+// requestToken returns the token the request signs in with: the cookie with the given
+// name in cookie mode, the bearer token otherwise.
+func (s *APIV1Service) requestToken(c *gin.Context, cookie string) (token string, fromCookie bool, err error) {
+	if !s.cookieMode(c) {
+		token, err = getBearerToken(c)
+		return token, false, err
+	}
+
+	token, err = c.Cookie(cookie)
+	if err != nil || token == "" {
+		return "", true, errors.New(ErrUnauthorized)
+	}
+
+	return token, true, nil
+}
+
+// validCSRF checks the double submitted CSRF token of a request signed in with cookies:
+// the header has to match the CSRF cookie. Another site can make the browser send the
+// cookie, but can't read it to set the header. Safe methods need no token.
+func validCSRF(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	cookie, err := c.Cookie(csrfCookie)
+	if err != nil || cookie == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(c.GetHeader(csrfHeader))) == 1
+}
+
+// respondWithTokens sends a freshly issued pair of tokens along with body. In cookie mode
+// they're set as cookies and left out of the body.
+func (s *APIV1Service) respondWithTokens(c *gin.Context, status int, body gin.H, accessToken, refreshToken string) {
+	if !s.cookieMode(c) {
+		body["access_token"] = accessToken
+		body["refresh_token"] = refreshToken
+		c.JSON(status, body)
+		return
+	}
+
+	csrfToken, err := pkg.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessExp := time.Hour * time.Duration(s.config.JWT.Exp)
+	refreshExp := time.Hour * time.Duration(s.config.JWT.RefExp)
+	setAuthCookie(c, accessCookie, accessToken, authCookiePath, accessExp, true)
+	setAuthCookie(c, refreshCookie, refreshToken, authCookiePath, refreshExp, true)
+	setAuthCookie(c, csrfCookie, csrfToken, "/", refreshExp, false)
+
+	c.JSON(status, body)
+}
+
+// clearAuthCookies signs the browser out in cookie mode.
+func (s *APIV1Service) clearAuthCookies(c *gin.Context) {
+	if s.config.Auth.Mode != authModeCookie {
+		return
+	}
+
+	setAuthCookie(c, accessCookie, "", authCookiePath, -time.Second, true)
+	setAuthCookie(c, refreshCookie, "", authCookiePath, -time.Second, true)
+	setAuthCookie(c, csrfCookie, "", "/", -time.Second, false)
+}
+
+// setAuthCookie sets a Secure, SameSite=Strict cookie lasting maxAge, a negative maxAge
+// deletes it. Browsers accept Secure cookies from http://localhost during development.
+func setAuthCookie(c *gin.Context, name, value, path string, maxAge time.Duration, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   true,
+		HttpOnly: httpOnly,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
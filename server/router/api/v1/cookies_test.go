@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+func TestValidCSRF(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		cookie string
+		header string
+		want   bool
+	}{
+		{name: "GET", method: http.MethodGet, want: true},
+		{name: "HEAD", method: http.MethodHead, want: true},
+		{name: "OPTIONS", method: http.MethodOptions, want: true},
+		{name: "matching header", method: http.MethodPost, cookie: "csrf", header: "csrf", want: true},
+		{name: "missing header", method: http.MethodPost, cookie: "csrf"},
+		{name: "wrong header", method: http.MethodDelete, cookie: "csrf", header: "other"},
+		{name: "missing cookie", method: http.MethodPatch, header: "csrf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(tt.method, "/", nil)
+			if tt.cookie != "" {
+				c.Request.AddCookie(&http.Cookie{Name: csrfCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				c.Request.Header.Set(csrfHeader, tt.header)
+			}
+
+			if got := validCSRF(c); got != tt.want {
+				t.Errorf("validCSRF = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// cookieHeader signs a request in with cookies, sending csrf back in the header if set.
+func cookieHeader(cookies map[string]string, csrf string) http.Header {
+	header := http.Header{}
+	for name, value := range cookies {
+		header.Add("Cookie", (&http.Cookie{Name: name, Value: value}).String())
+	}
+	if csrf != "" {
+		header.Set(csrfHeader, csrf)
+	}
+	return header
+}
+
+func TestCheckJWTCookie(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		csrf   string
+		status int
+	}{
+		{name: "safe method", method: http.MethodGet, status: http.StatusOK},
+		{name: "matching header", method: http.MethodPost, csrf: "csrf", status: http.StatusOK},
+		{name: "missing header", method: http.MethodPost, status: http.StatusForbidden},
+		{name: "wrong header", method: http.MethodPost, csrf: "other", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			s.config.Auth.Mode = authModeCookie
+			if tt.status == http.StatusOK {
+				expectSignedIn(mock, 1, 5, database.RoleAuthor, false)
+			}
+
+			header := cookieHeader(map[string]string{accessCookie: accessJWT(t, s, 1, 5), csrfCookie: "csrf"}, tt.csrf)
+			w := serve(t, tt.method, "/posts", "/posts", nil, header,
+				s.CheckJWT(), func(c *gin.Context) { c.Status(http.StatusOK) })
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestLogoutCookie(t *testing.T) {
+	logout := func(t *testing.T, s *APIV1Service, csrf string) *httptest.ResponseRecorder {
+		t.Helper()
+		cookies := map[string]string{refreshCookie: refreshJWT(t, s, 1, 5, "token"), csrfCookie: "csrf"}
+		return serve(t, http.MethodPost, "/auth/logout", "/auth/logout", nil, cookieHeader(cookies, csrf), s.logoutHandler)
+	}
+
+	t.Run("revokes the session, then clears the cookies", func(t *testing.T) {
+		s, mock := newTestService(t)
+		s.config.Auth.Mode = authModeCookie
+		mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(pkg.HashToken("token")).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		w := logout(t, s, "csrf")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+		}
+		if n := len(w.Result().Cookies()); n != 3 {
+			t.Errorf("cleared %d cookies, want 3", n)
+		}
+	})
+
+	// Another site can't sign the browser out.
+	t.Run("without the CSRF token", func(t *testing.T) {
+		s, _ := newTestService(t)
+		s.config.Auth.Mode = authModeCookie
+
+		w := logout(t, s, "")
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body)
+		}
+		if cookies := w.Result().Cookies(); len(cookies) != 0 {
+			t.Errorf("cleared cookies %v without the CSRF token", cookies)
+		}
+	})
+}
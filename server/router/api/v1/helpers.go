@@ -5,9 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"maps"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -16,19 +16,25 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/gosimple/slug"
 	"github.com/yuin/goldmark"
 	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
 
+	"github.com/joybiswas007/blog/internal/cache"
 	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/keyring"
+	"github.com/joybiswas007/blog/pkg"
 )
 
 // Token type constants define the types of JWT tokens used in the application.
 const (
 	TokenTypeAccess  = "access"  // Access token for API authentication
 	TokenTypeRefresh = "refresh" // Refresh token for obtaining new access tokens
+	TokenTypePost    = "post"    // Post token for reading an unlocked password protected post
+	TokenTypeMFA     = "mfa"     // Challenge token between the password and the two-factor step of a login
 )
 
 // Authentication error messages returned by the auth middleware and handlers.
@@ -57,8 +63,14 @@ var (
 	// ErrInvalidAuthFormat is returned when the Authorization header doesn't follow the 'Bearer <token>' format.
 	ErrInvalidAuthFormat = "Invalid Authorization format. Expected 'Bearer <token>'"
 
+	// ErrAccountDeactivated is returned when a deactivated user tries to sign in or use a token.
+	ErrAccountDeactivated = "This account has been deactivated."
+
 	// ErrNotEnoughPerm is returned when a user lacks the required permissions for a resource.
 	ErrNotEnoughPerm = "You don't have enough permission to access this resource."
+
+	// ErrTwoFactorRequired is returned when an admin who hasn't enabled two-factor auth needs their rights.
+	ErrTwoFactorRequired = "Enable two-factor authentication to use your admin rights."
 )
 
 // getBearerToken extracts the Bearer token from the Authorization header.
@@ -85,28 +97,32 @@ func getBearerToken(c *gin.Context) (string, error) {
 }
 
 // generateJWT creates a signed JSON Web Token (JWT) with the provided data as claims.
-// The token is signed using the provided secret key and the HS256 (HMAC-SHA256) algorithm.
-func generateJWT(data map[string]any, secretKey string) (string, error) {
-	// Create a new JWT token using the HS256 (HMAC-SHA256) signing method.
-	t := jwt.New(jwt.SigningMethodHS256)
-
-	// Cast the token's claims to a MapClaims type to add custom data.
-	claims := t.Claims.(jwt.MapClaims)
+// The token is signed with the newest key of the keyring. Until a key has been generated
+// it's signed using the provided secret key and the HS256 (HMAC-SHA256) algorithm.
+func (s *APIV1Service) generateJWT(ctx context.Context, data map[string]any, secretKey string) (string, error) {
+	claims := jwt.MapClaims{}
 
 	// Add the provided key-value pairs to the token's claims.
 	maps.Copy(claims, data)
 
-	// Sign the token using the provided secret key.
-	token, err := t.SignedString([]byte(secretKey))
-	if err != nil {
-		return "", err
+	token, err := s.keys.Sign(ctx, claims)
+	if !errors.Is(err, keyring.ErrNoKeys) {
+		return token, err
 	}
 
-	return token, nil
+	// Sign the token using the provided secret key.
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
 }
 
-// parseJWT parses a JWT token and validates it, returning the claims if valid.
-func parseJWT(tokenString, secretKey string) (jwt.MapClaims, error) {
+// parseJWT parses a JWT token and validates it, returning the claims if valid. Tokens
+// are checked against the keyring, or against the secret key until a key has been
+// generated and as long after as tokens signed with it live.
+func (s *APIV1Service) parseJWT(ctx context.Context, tokenString, secretKey string) (jwt.MapClaims, error) {
+	claims, err := s.keys.Parse(ctx, tokenString)
+	if !errors.Is(err, keyring.ErrNoKeys) && !errors.Is(err, keyring.ErrLegacyToken) {
+		return claims, err
+	}
+
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
 		// Ensure the signing method is HMAC
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -128,30 +144,63 @@ func parseJWT(tokenString, secretKey string) (jwt.MapClaims, error) {
 	return claims, nil
 }
 
-// generateTokens generates access and refresh tokens for a user and returns both tokens.
-func generateTokens(userID int64, s *APIV1Service) (accessToken, refreshToken string, err error) {
+// maxUserAgentLength caps the user agent stored with a session.
+const maxUserAgentLength = 512
+
+// generateTokens signs a user in. It starts a session, recording where the request came
+// from, and returns an access token along with the first refresh token of the session.
+func generateTokens(c *gin.Context, userID int64, s *APIV1Service) (accessToken, refreshToken string, err error) {
+	tokenID, err := pkg.GenerateToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+
+	session := &database.Session{
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		UserAgent: userAgent,
+		ExpiresAt: time.Now().Add(time.Hour * time.Duration(s.config.JWT.RefExp)),
+	}
+	err = s.db.Sessions.Create(c.Request.Context(), session, pkg.HashToken(tokenID))
+	if err != nil {
+		return "", "", err
+	}
+
+	return signTokens(c.Request.Context(), userID, session.ID, tokenID, session.ExpiresAt, s)
+}
+
+// signTokens signs an access token for a session of a user and a refresh token carrying
+// tokenID, the ID the refresh token is stored under.
+func signTokens(ctx context.Context, userID, sessionID int64, tokenID string, refreshExpiresAt time.Time, s *APIV1Service) (accessToken, refreshToken string, err error) {
 	// Access token config
 	accessExp := s.config.JWT.Exp
 	accessClaims := map[string]any{
 		"user_id": userID,
+		"sid":     sessionID,
 		"type":    TokenTypeAccess,
 		"exp":     time.Now().Add(time.Hour * time.Duration(accessExp)).Unix(),
 	}
 
-	accessToken, err = generateJWT(accessClaims, s.config.JWT.Secret)
+	accessToken, err = s.generateJWT(ctx, accessClaims, s.config.JWT.Secret)
 	if err != nil {
 		return "", "", err
 	}
 
 	// Refresh token config
-	refreshExp := s.config.JWT.RefExp
 	refreshClaims := map[string]any{
 		"user_id": userID,
+		"sid":     sessionID,
 		"type":    TokenTypeRefresh,
-		"exp":     time.Now().Add(time.Hour * time.Duration(refreshExp)).Unix(),
+		"jti":     tokenID,
+		"exp":     refreshExpiresAt.Unix(),
 	}
 
-	refreshToken, err = generateJWT(refreshClaims, s.config.JWT.RefSecret)
+	refreshToken, err = s.generateJWT(ctx, refreshClaims, s.config.JWT.RefSecret)
 	if err != nil {
 		return "", "", err
 	}
@@ -159,31 +208,164 @@ func generateTokens(userID int64, s *APIV1Service) (accessToken, refreshToken st
 	return accessToken, refreshToken, nil
 }
 
+// slugPattern matches lowercase words separated by single hyphens, e.g. "weekly-notes-42".
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// makeSlug returns the slug a post should be stored under: the custom slug if one
+// was given, otherwise a slug generated from the title.
+func makeSlug(customSlug, title string) (string, error) {
+	if customSlug == "" {
+		customSlug = slug.Make(title)
+		if customSlug == "" {
+			return "", errors.New("unable to generate a slug from the title, please provide one")
+		}
+		return customSlug, nil
+	}
+
+	if len(customSlug) > 255 || !slugPattern.MatchString(customSlug) {
+		return "", errors.New("slug may only contain lowercase letters, numbers and single hyphens")
+	}
+
+	return customSlug, nil
+}
+
+// canPublish reports whether role may publish, schedule, unpublish and delete posts.
+func canPublish(role string) bool {
+	return role == database.RoleAdmin || role == database.RoleEditor
+}
+
+// canEditPost reports whether the user with the given ID and role may edit post.
+// Co-authors may edit a post just like its owner.
+func canEditPost(userID int64, role string, post *database.Post) bool {
+	switch role {
+	case database.RoleAdmin, database.RoleEditor:
+		return true
+	case database.RoleAuthor:
+		return isAuthor(userID, post)
+	case database.RoleContributor:
+		return isAuthor(userID, post) && post.Visibility == database.VisibilityDraft
+	default:
+		return false
+	}
+}
+
+// isAuthor reports whether the user with the given ID owns or co-authored post.
+func isAuthor(userID int64, post *database.Post) bool {
+	if post.UserID == userID {
+		return true
+	}
+	for _, a := range post.Authors {
+		if a.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// authorNames joins the names of the post authors for bylines, falling back to the owner.
+func authorNames(post *database.Post) string {
+	if len(post.Authors) == 0 {
+		return post.Author
+	}
+	names := make([]string, len(post.Authors))
+	for i, a := range post.Authors {
+		names[i] = a.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// etag formats a post version as an HTTP entity tag.
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion returns the post version the If-Match header requires.
+// ok is false if the header is missing or "*". A tag that isn't a post version
+// returns -1, which never matches.
+func ifMatchVersion(c *gin.Context) (version int, ok bool) {
+	tag := strings.TrimSpace(c.GetHeader("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, false
+	}
+
+	tag = strings.TrimPrefix(tag, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return -1, true
+	}
+
+	version, err = strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return -1, true
+	}
+	return version, true
+}
+
+// updateErrorStatus maps a post update error to an HTTP status code. Version mismatches
+// are reported as 412 when the client sent If-Match and as 409 otherwise.
+func updateErrorStatus(err error, precondition bool) int {
+	switch {
+	case errors.Is(err, database.ErrEditConflict) && precondition:
+		return http.StatusPreconditionFailed
+	case errors.Is(err, database.ErrEditConflict):
+		return http.StatusConflict
+	case errors.Is(err, database.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 // getIDFromParam extracts the "id" parameter from the request.
 // converts it to an integer, and returns an error if it's missing or invalid.
 func getIDFromParam(c *gin.Context) (int, error) {
-	// Get the ID from the URL parameter
-	idStr := c.Param("id")
-	if idStr == "" {
-		return 0, fmt.Errorf("missing parameter: id is required")
+	return getIntParam(c, "id")
+}
+
+// getIntParam extracts the named URL parameter from the request,
+// converts it to an integer, and returns an error if it's missing or invalid.
+func getIntParam(c *gin.Context, name string) (int, error) {
+	// Get the value from the URL parameter
+	valueStr := c.Param(name)
+	if valueStr == "" {
+		return 0, fmt.Errorf("missing parameter: %s is required", name)
 	}
 
-	// Convert the ID to an integer
-	id, err := strconv.Atoi(idStr)
+	// Convert the value to an integer
+	value, err := strconv.Atoi(valueStr)
 	if err != nil {
-		return 0, fmt.Errorf("invalid ID: %w", err)
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
 	}
 
-	return id, nil
+	return value, nil
 }
 
-func getPosts(c *gin.Context, s *APIV1Service) ([]*database.Post, database.Filter, int, error) {
+// sortableColumns lists the blog_posts columns that posts can be ordered by.
+var sortableColumns = map[string]bool{
+	"id":           true,
+	"title":        true,
+	"views":        true,
+	"created_at":   true,
+	"updated_at":   true,
+	"published_at": true,
+}
+
+// getPosts lists posts using the query parameters of the request. Only published posts
+// are listed unless withHidden is set for the dashboard, which may filter on is_published
+// and status.
+func getPosts(c *gin.Context, s *APIV1Service, withHidden bool) ([]*database.Post, database.Filter, int, error) {
 	// Parse limit and offset query parameters with default values.
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
 	tag := c.Query("tag")
-	orderBy := c.DefaultQuery("order_by", "created_at")
-	sort := c.DefaultQuery("sort", "DESC")
+	orderBy := c.DefaultQuery("order_by", "published_at")
+	sort := strings.ToUpper(c.DefaultQuery("sort", "DESC"))
+
+	// Only allow known columns and directions, they end up in the ORDER BY clause.
+	if !sortableColumns[orderBy] {
+		orderBy = "published_at"
+	}
+	if sort != "ASC" && sort != "DESC" {
+		sort = "DESC"
+	}
 
 	isPublishedStr := c.DefaultQuery("is_published", "true")
 	isPublished, err := strconv.ParseBool(isPublishedStr)
@@ -192,16 +374,32 @@ func getPosts(c *gin.Context, s *APIV1Service) ([]*database.Post, database.Filte
 		isPublished = true
 	}
 
-	// Convert limit and offset to integers and validate them.
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 5
+	// status narrows the listing down to drafts, scheduled, unlisted, private or published posts.
+	status := c.Query("status")
+	switch status {
+	case database.StatusPublished:
+		isPublished = true
+	case database.StatusDraft, database.StatusScheduled, database.StatusUnlisted, database.StatusPrivate:
+		isPublished = false
+	default:
+		status = ""
 	}
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+
+	// Readers only ever get published posts, whatever they ask for, so the total and
+	// the pages only count those.
+	if !withHidden {
+		isPublished = true
+		status = ""
 	}
 
+	// author_id lists posts by one author, co-authored ones included.
+	authorID, err := strconv.ParseInt(c.Query("author_id"), 10, 64)
+	if err != nil || authorID < 0 {
+		authorID = 0
+	}
+
+	limit, offset := getPagination(c)
+
 	// Create a filter and fetch posts from the database.
 	filter := database.Filter{
 		Limit:       limit,
@@ -210,6 +408,8 @@ func getPosts(c *gin.Context, s *APIV1Service) ([]*database.Post, database.Filte
 		OrderBy:     orderBy,
 		Sort:        sort,
 		IsPublished: isPublished,
+		Status:      status,
+		AuthorID:    authorID,
 	}
 	posts, totalPost, err := s.db.Posts.GetAll(c.Request.Context(), filter)
 	if err != nil {
@@ -218,6 +418,19 @@ func getPosts(c *gin.Context, s *APIV1Service) ([]*database.Post, database.Filte
 	return posts, filter, totalPost, nil
 }
 
+// getPagination reads the limit and offset query parameters.
+func getPagination(c *gin.Context) (limit, offset int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+	offset, err = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
 // MarkdownToHTML converts a Markdown string to HTML with syntax highlighting.
 func MarkdownToHTML(content string) string {
 	md := goldmark.New(
@@ -243,38 +456,9 @@ func MarkdownToHTML(content string) string {
 	return buf.String()
 }
 
-// deleteCacheKey invalidates cached data for blog posts, tags, and archives by deleting matching keys from Redis.
+// deleteCacheKey invalidates cached data for blog posts, tags, archives, and series by deleting matching keys from Redis.
 func deleteCacheKey(cacheStore *persist.RedisStore) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	patterns := []string{
-		"/api/v1/posts*",           // All post lists
-		"/api/v1/posts/tags",       // Single tag key
-		"/api/v1/posts/archives",   // Single archive key
-		"/api/v1/posts/archives/*", // All archive keys
-	}
-
-	for _, pattern := range patterns {
-		// For pattern keys, use SCAN.
-		var cursor uint64
-		for {
-			keys, nextCursor, err := cacheStore.RedisClient.Scan(ctx, cursor, pattern, 100).Result()
-			if err != nil {
-				log.Printf("Scan error for pattern %s: %v", pattern, err)
-				break
-			}
-			if len(keys) > 0 {
-				if err := cacheStore.RedisClient.Del(ctx, keys...).Err(); err != nil {
-					log.Printf("Failed to delete keys %v: %v", keys, err)
-				}
-			}
-			cursor = nextCursor
-			if cursor == 0 {
-				break
-			}
-		}
-	}
+	cache.Invalidate(cacheStore)
 }
 
 // inputValidationErrors processes validator errors and responds with a formatted JSON error.
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/keyring"
+)
+
+func TestParseJWTAfterFirstKey(t *testing.T) {
+	s, _ := newTestService(t)
+
+	// Signed with the secret before any key existed.
+	legacy, err := s.generateJWT(context.Background(), map[string]any{"user_id": 1}, s.config.JWT.Secret)
+	if err != nil {
+		t.Fatalf("generateJWT: %v", err)
+	}
+
+	key, err := keyring.Generate(keyring.EdDSA)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	rec, err := key.Record("key-secret")
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	load := func(context.Context) ([]*database.SigningKey, error) { return []*database.SigningKey{rec}, nil }
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Forged with the secret long after the first key, e.g. because the secret leaked.
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1}).
+		SignedString([]byte(s.config.JWT.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec.CreatedAt = time.Now()
+	s.keys = keyring.New(load, "key-secret", legacyTokenGrace(s.config), logger)
+	if _, err := s.parseJWT(context.Background(), legacy, s.config.JWT.Secret); err != nil {
+		t.Errorf("token signed with the secret rejected right after the first key: %v", err)
+	}
+	if _, err := s.parseJWT(context.Background(), legacy, s.config.JWT.RefSecret); err == nil {
+		t.Error("token signed with the access secret accepted as a refresh token")
+	}
+
+	rec.CreatedAt = time.Now().Add(-legacyTokenGrace(s.config))
+	s.keys = keyring.New(load, "key-secret", legacyTokenGrace(s.config), logger)
+	if _, err := s.parseJWT(context.Background(), forged, s.config.JWT.Secret); err == nil {
+		t.Error("token signed with the secret accepted after the grace period")
+	}
+}
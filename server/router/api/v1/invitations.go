@@ -0,0 +1,200 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// defaultInvitationTTL is how many hours an invitation works when no lifetime is given.
+const defaultInvitationTTL = 72
+
+// registerInvitationRoutes handles the public side of invitations: looking one up and accepting it.
+// Invitations are never cached.
+func registerInvitationRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	invitations := rg.Group("invitations")
+	invitations.GET(":token", s.invitationHandler)
+	invitations.POST(":token/accept", s.acceptInvitationHandler)
+}
+
+// registerInvitationAdminRoutes handles sending and revoking invitations, admins only.
+func registerInvitationAdminRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	invitations := rg.Group("invitations", s.RequireRole(database.RoleAdmin))
+	invitations.GET("", s.invitationsHandler)
+	invitations.POST("", s.createInvitationHandler)
+	invitations.DELETE(":id", s.revokeInvitationHandler)
+}
+
+// invitationHandler shows the email and role of a pending invitation so the invitee
+// knows which account they're about to create.
+func (s *APIV1Service) invitationHandler(c *gin.Context) {
+	c.Header("Cache-Control", "private, no-store")
+
+	inv, err := s.db.Invitations.GetByToken(c.Request.Context(), pkg.HashToken(c.Param("token")))
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitation": inv})
+}
+
+// acceptInvitationHandler creates the account of an invitee with the password they chose
+// and signs them in.
+func (s *APIV1Service) acceptInvitationHandler(c *gin.Context) {
+	var input struct {
+		Name     string `json:"name" binding:"required,max=255"`
+		Handle   string `json:"handle" binding:"omitempty,max=64"`
+		Password string `json:"password" binding:"required,min=8,max=72"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	if input.Handle != "" && !database.ValidHandle(input.Handle) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "handle may only contain lowercase letters, digits and dashes"})
+		return
+	}
+
+	user := &database.User{
+		Name:   input.Name,
+		Handle: input.Handle,
+	}
+
+	err = user.Password.Set(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Invitations.Accept(c.Request.Context(), pkg.HashToken(c.Param("token")), user)
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditInvitationAccept, database.AuditTargetUser, user.ID, map[string]any{"email": user.Email, "role": user.Role})
+
+	accessToken, refreshToken, err := generateTokens(c, user.ID, s)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.respondWithTokens(c, http.StatusCreated, gin.H{"message": "Welcome aboard!", "user": user}, accessToken, refreshToken)
+}
+
+func (s *APIV1Service) invitationsHandler(c *gin.Context) {
+	invitations, err := s.db.Invitations.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// createInvitationHandler invites someone to create an account. The token is only
+// returned here, it can't be looked up again later.
+func (s *APIV1Service) createInvitationHandler(c *gin.Context) {
+	var input struct {
+		Email     string `json:"email" binding:"required,email"`
+		Role      string `json:"role" binding:"omitempty,oneof=admin editor author contributor"`
+		ExpiresIn int    `json:"expires_in" binding:"omitempty,min=1,max=720"` // Hours until the invitation expires, at most 30 days
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	if input.Role == "" {
+		input.Role = database.RoleAuthor
+	}
+	if input.ExpiresIn == 0 {
+		input.ExpiresIn = defaultInvitationTTL
+	}
+
+	// Don't hand out invitations for accounts that can't be created.
+	_, err = s.db.Users.GetByEmail(c.Request.Context(), input.Email)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusConflict, gin.H{"error": database.ErrDuplicateEmail.Error()})
+		return
+	case !errors.Is(err, database.ErrRecordNotFound):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv := &database.Invitation{
+		Email:     input.Email,
+		Role:      input.Role,
+		ExpiresAt: time.Now().Add(time.Duration(input.ExpiresIn) * time.Hour),
+	}
+
+	err = s.db.Invitations.Create(c.Request.Context(), inv, int64(c.GetFloat64("user_id")), pkg.HashToken(token))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditInvitationCreate, database.AuditTargetInvitation, int64(inv.ID), map[string]any{"email": inv.Email, "role": inv.Role})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Invitation created successfully!",
+		"token":      token,
+		"url":        invitationURL(s.config.Blog.URL, token),
+		"invitation": inv,
+	})
+}
+
+func (s *APIV1Service) revokeInvitationHandler(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Invitations.Delete(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditInvitationRevoke, database.AuditTargetInvitation, int64(id), nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully!"})
+}
+
+// invitationURL returns the frontend link an invitee opens to accept the invitation.
+func invitationURL(siteURL, token string) string {
+	return fmt.Sprintf("%s/invite/%s", siteURL, token)
+}
+
+// invitationErrorStatus maps invitation errors to HTTP status codes.
+func invitationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, database.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrDuplicateEmail), errors.Is(err, database.ErrDuplicateHandle):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jwksHandler publishes the public keys tokens are signed with, so other services can
+// verify them. Keys that were rotated out stay listed until their grace period ends.
+func (s *APIV1Service) jwksHandler(c *gin.Context) {
+	set, err := s.keys.JWKS(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// postLockTTL is how long an edit lock lasts without being refreshed by the editor.
+const postLockTTL = 2 * time.Minute
+
+// registerLockRoutes handles advisory edit locks of posts, protected by auth.
+// The dashboard takes the lock when opening the editor and refreshes it while editing,
+// so other editors can be warned. Locks never block updates, versions do that.
+func registerLockRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	lock := rg.Group(":id/lock", s.CanEditPost())
+	lock.GET("", s.getPostLockHandler)
+	lock.POST("", s.acquirePostLockHandler)
+	lock.DELETE("", s.releasePostLockHandler)
+}
+
+func (s *APIV1Service) getPostLockHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lock, err := s.db.Locks.Get(c.Request.Context(), pid)
+	if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lock": lock})
+}
+
+// acquirePostLockHandler takes or refreshes the edit lock of a post.
+// If someone else is editing the post, 409 is returned along with their lock.
+func (s *APIV1Service) acquirePostLockHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	_, err = s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(lockErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	lock, err := s.db.Locks.Acquire(c.Request.Context(), pid, int64(uid), postLockTTL)
+	if err != nil {
+		if errors.Is(err, database.ErrPostLocked) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("%s is editing this post", lock.Name),
+				"lock":  lock,
+			})
+			return
+		}
+		c.JSON(lockErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lock": lock})
+}
+
+func (s *APIV1Service) releasePostLockHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	err = s.db.Locks.Release(c.Request.Context(), pid, int64(uid))
+	if err != nil {
+		c.JSON(lockErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lock released"})
+}
+
+// lockErrorStatus maps an edit lock error to an HTTP status code.
+func lockErrorStatus(err error) int {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -1,28 +1,52 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 
 	"golang.org/x/time/rate"
+
+	"github.com/joybiswas007/blog/internal/database"
 )
 
+// publisherRoles may publish, unpublish and delete any post.
+var publisherRoles = []string{database.RoleAdmin, database.RoleEditor}
+
 // CheckJWT validates a JWT token from the "Authorization" header.
 // It performs the following checks:
 // Ensures the "Authorization" header exists and is in "Bearer <token>" format.
+// In cookie mode the token may come from the access cookie instead, then state changing
+// requests need the CSRF token.
 // Verifies the token signature, expiration, and required claims (user_id).
 // Cross-checks the token's user_id against the database for validity.
+// Rejects deactivated users and tokens of sessions that ended.
+// Personal access tokens are refused, routes open to them use requireScope instead.
 func (s *APIV1Service) CheckJWT() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, err := getBearerToken(c)
+		token, fromCookie, err := s.requestToken(c, accessCookie)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
-		claims, err := parseJWT(token, s.config.JWT.Secret)
+		if fromCookie && !validCSRF(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrInvalidCSRFToken})
+			return
+		}
+
+		if strings.HasPrefix(token, accessTokenPrefix) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Personal access tokens can't be used for this endpoint."})
+			return
+		}
+
+		claims, err := s.parseJWT(c.Request.Context(), token, s.config.JWT.Secret)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthorized})
 			return
@@ -53,8 +77,87 @@ func (s *APIV1Service) CheckJWT() gin.HandlerFunc {
 			return
 		}
 
-		// Pass user ID to handlers.
+		if u.DeactivatedAt != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrAccountDeactivated})
+			return
+		}
+
+		// Tokens issued before sessions were tracked carry no session.
+		sid, ok := claims["sid"].(float64)
+		if !ok || sid == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrTokenInvalidOrExpired})
+			return
+		}
+
+		err = s.db.Sessions.Touch(c.Request.Context(), int64(sid), u.ID)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrTokenInvalidOrExpired})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Pass user ID, session ID and role to handlers.
 		c.Set("user_id", uid)
+		c.Set("session_id", sid)
+		c.Set("role", s.userRole(c, u))
+		c.Next()
+	}
+}
+
+// userRole returns the role u signs in with. Where admins have to use two-factor auth,
+// an admin who hasn't enabled it only gets the rights of an author until they do.
+func (s *APIV1Service) userRole(c *gin.Context, u *database.User) string {
+	if s.config.Auth.RequireAdmin2FA && u.Role == database.RoleAdmin && !u.TwoFactorEnabled {
+		c.Set("two_factor_required", true)
+		return database.RoleAuthor
+	}
+	return u.Role
+}
+
+// RequireRole only lets users with one of the given roles through.
+// It must run after CheckJWT.
+func (s *APIV1Service) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(roles, c.GetString("role")) {
+			if c.GetBool("two_factor_required") {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrTwoFactorRequired})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrNotEnoughPerm})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CanEditPost only lets users through who may edit the post in the "id" parameter:
+// admins and editors edit any post, authors their own posts and contributors their own drafts.
+// It must run after CheckJWT.
+func (s *APIV1Service) CanEditPost() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pid, err := getIDFromParam(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		post, err := s.db.Posts.Get(c.Request.Context(), pid)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !canEditPost(int64(c.GetFloat64("user_id")), c.GetString("role"), post) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrNotEnoughPerm})
+			return
+		}
 		c.Next()
 	}
 }
@@ -79,3 +182,22 @@ func (s *APIV1Service) RateLimiter() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// countRequestScript increments a counter, starting its window on the first increment.
+var countRequestScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n`)
+
+// overLimit counts a request against key and reports whether more than limit were made
+// within window. The count lives in Redis, so it holds across instances and restarts.
+func (s *APIV1Service) overLimit(ctx context.Context, key string, limit int64, window time.Duration) (bool, error) {
+	n, err := countRequestScript.Run(ctx, s.redisStore.RedisClient, []string{key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return n > limit, nil
+}
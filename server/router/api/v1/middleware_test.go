@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// accessJWT signs an access token for session sid of userID.
+func accessJWT(t *testing.T, s *APIV1Service, userID, sid int64) string {
+	t.Helper()
+
+	claims := map[string]any{
+		"user_id": userID,
+		"sid":     sid,
+		"type":    TokenTypeAccess,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+	token, err := s.generateJWT(context.Background(), claims, s.config.JWT.Secret)
+	if err != nil {
+		t.Fatalf("generateJWT: %v", err)
+	}
+	return token
+}
+
+// expectSignedIn expects CheckJWT to look up userID with the given role and find its
+// session sid active.
+func expectSignedIn(mock pgxmock.PgxPoolIface, userID, sid int64, role string, twoFactor bool) {
+	now := time.Now()
+	mock.ExpectQuery(`FROM users\s+WHERE id = \$1`).WithArgs(userID).WillReturnRows(
+		pgxmock.NewRows([]string{
+			"id", "name", "email", "password_hash", "role", "handle", "bio", "avatar_url", "links",
+			"deactivated_at", "totp_enabled", "created_at", "updated_at",
+		}).AddRow(
+			userID, "Ada", "ada@example.com", []byte{}, role, "ada", "", "", nil,
+			nil, twoFactor, now, now,
+		))
+	mock.ExpectQuery(`SELECT last_seen_at\s+FROM sessions`).WithArgs(sid, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"last_seen_at"}).AddRow(now))
+}
+
+func TestRequireAdmin2FA(t *testing.T) {
+	tests := []struct {
+		name      string
+		require   bool
+		twoFactor bool
+		status    int
+		err       string
+	}{
+		{name: "not required", status: http.StatusOK},
+		{name: "required and enabled", require: true, twoFactor: true, status: http.StatusOK},
+		{name: "required but not enabled", require: true, status: http.StatusForbidden, err: ErrTwoFactorRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			s.config.Auth.RequireAdmin2FA = tt.require
+			expectSignedIn(mock, 1, 5, database.RoleAdmin, tt.twoFactor)
+
+			header := http.Header{"Authorization": {"Bearer " + accessJWT(t, s, 1, 5)}}
+			w := serve(t, http.MethodGet, "/audit-logs", "/audit-logs", nil, header,
+				s.CheckJWT(), s.RequireRole(database.RoleAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.err != "" {
+				if got := decode(t, w)["error"]; got != tt.err {
+					t.Errorf("error = %q, want %q", got, tt.err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/mailer"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// passwordResetTTL is how long a password reset link works.
+const passwordResetTTL = time.Hour
+
+// Reset links are limited per address, so the endpoint can't be used to flood inboxes,
+// and per email, so a single inbox isn't flooded from many addresses.
+const (
+	forgotPasswordIPLimit    = 10        // Requests per address within forgotPasswordWindow
+	forgotPasswordEmailLimit = 3         // Requests per email within forgotPasswordWindow
+	forgotPasswordWindow     = time.Hour // Window requests are counted over
+)
+
+// Redis key prefixes of the forgot password limits.
+const (
+	forgotPasswordIPPrefix    = "forgot-password:ip:"
+	forgotPasswordEmailPrefix = "forgot-password:email:"
+)
+
+// forgotPasswordHandler emails a password reset link. It answers the same way whether
+// or not the email belongs to an active account, so it can't be used to probe for users.
+// Requests are limited per address and per email.
+func (s *APIV1Service) forgotPasswordHandler(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	over, err := s.overLimit(c.Request.Context(), forgotPasswordIPPrefix+c.ClientIP(), forgotPasswordIPLimit, forgotPasswordWindow)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if over {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many password reset requests, try again later."})
+		return
+	}
+
+	response := gin.H{"message": "If an account exists for this email, a reset link is on its way."}
+
+	// Over the limit of an email the answer stays the same, it must not tell whether
+	// the account exists either.
+	emailHash := hex.EncodeToString(pkg.HashToken(strings.ToLower(input.Email)))
+	over, err = s.overLimit(c.Request.Context(), forgotPasswordEmailPrefix+emailHash, forgotPasswordEmailLimit, forgotPasswordWindow)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if over {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	user, err := s.db.Users.GetByEmail(c.Request.Context(), input.Email)
+	if err != nil || user.DeactivatedAt != nil {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.PasswordResets.Create(c.Request.Context(), user.ID, pkg.HashToken(token), time.Now().Add(passwordResetTTL))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Reset your %s password", s.config.Blog.Name),
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"someone asked to reset the password of your account. Open this link within an hour to choose a new one:\n\n"+
+			"%s/reset-password/%s\n\n"+
+			"If that wasn't you, you can ignore this email, your password stays the same.\n",
+			user.Name, s.config.Blog.URL, token),
+	}
+
+	// Send in the background, a slow mail server must not give away that the account exists.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.logger.Error("failed to send password reset email", "user_id", user.ID, "error", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// resetPasswordHandler sets a new password using the token from a reset link, then signs
+// the user out everywhere and revokes their access tokens.
+func (s *APIV1Service) resetPasswordHandler(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=8,max=72"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	var user database.User
+	err = user.Password.Set(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Signs the user out everywhere too.
+	revoked, err := s.db.PasswordResets.Consume(c.Request.Context(), pkg.HashToken(input.Token), &user)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This reset link is invalid, expired or was already used."})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserPasswordReset, database.AuditTargetUser, user.ID, map[string]any{"access_tokens_revoked": revoked})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully! You can sign in now."})
+}
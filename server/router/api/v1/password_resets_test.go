@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+func forgotPassword(t *testing.T, s *APIV1Service, email, ip string) int {
+	t.Helper()
+	header := http.Header{"X-Forwarded-For": {ip}}
+	w := serve(t, http.MethodPost, "/auth/forgot-password", "/auth/forgot-password", gin.H{"email": email}, header,
+		s.forgotPasswordHandler)
+	return w.Code
+}
+
+func TestForgotPasswordLimits(t *testing.T) {
+	t.Run("per address", func(t *testing.T) {
+		s, mock := newTestService(t)
+		for i := range forgotPasswordIPLimit {
+			email := fmt.Sprintf("user%d@example.com", i)
+			mock.ExpectQuery(`FROM users`).WithArgs(email).WillReturnError(pgx.ErrNoRows)
+			if code := forgotPassword(t, s, email, "198.51.100.1"); code != http.StatusAccepted {
+				t.Fatalf("request %d: status = %d, want %d", i+1, code, http.StatusAccepted)
+			}
+		}
+
+		if code := forgotPassword(t, s, "another@example.com", "198.51.100.1"); code != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want %d", code, http.StatusTooManyRequests)
+		}
+	})
+
+	// Over the limit the answer doesn't change, but the account isn't even looked up.
+	t.Run("per email", func(t *testing.T) {
+		s, mock := newTestService(t)
+		for i := range forgotPasswordEmailLimit {
+			mock.ExpectQuery(`FROM users`).WithArgs("jane@example.com").WillReturnError(pgx.ErrNoRows)
+			if code := forgotPassword(t, s, "jane@example.com", fmt.Sprintf("198.51.100.%d", i)); code != http.StatusAccepted {
+				t.Fatalf("request %d: status = %d, want %d", i+1, code, http.StatusAccepted)
+			}
+		}
+
+		if code := forgotPassword(t, s, "Jane@Example.com", "198.51.100.99"); code != http.StatusAccepted {
+			t.Errorf("status = %d, want %d", code, http.StatusAccepted)
+		}
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	reset := func(t *testing.T, s *APIV1Service) int {
+		t.Helper()
+		body := gin.H{"token": "reset-token", "password": "new password"}
+		w := serve(t, http.MethodPost, "/auth/reset-password", "/auth/reset-password", body, nil, s.resetPasswordHandler)
+		return w.Code
+	}
+
+	t.Run("signs out everywhere", func(t *testing.T) {
+		s, mock := newTestService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM password_resets`).WithArgs(pkg.HashToken("reset-token")).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id"}).AddRow(4, int64(1)))
+		mock.ExpectExec(`UPDATE users SET password_hash`).WithArgs(pgxmock.AnyArg(), int64(1)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`UPDATE password_resets SET used_at`).WithArgs(4).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(int64(1)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		mock.ExpectExec(`DELETE FROM personal_access_tokens`).WithArgs(int64(1)).
+			WillReturnResult(pgxmock.NewResult("DELETE", 3))
+		mock.ExpectCommit()
+		expectAudit(mock, database.AuditUserPasswordReset)
+
+		if code := reset(t, s); code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", code, http.StatusOK)
+		}
+	})
+
+	t.Run("keeps the old password if signing out fails", func(t *testing.T) {
+		s, mock := newTestService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM password_resets`).WithArgs(pkg.HashToken("reset-token")).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id"}).AddRow(4, int64(1)))
+		mock.ExpectExec(`UPDATE users SET password_hash`).WithArgs(pgxmock.AnyArg(), int64(1)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`UPDATE password_resets SET used_at`).WithArgs(4).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(int64(1)).
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		if code := reset(t, s); code != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want %d", code, http.StatusInternalServerError)
+		}
+	})
+
+	t.Run("used token", func(t *testing.T) {
+		s, mock := newTestService(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM password_resets`).WithArgs(pkg.HashToken("reset-token")).
+			WillReturnError(pgx.ErrNoRows)
+		mock.ExpectRollback()
+
+		if code := reset(t, s); code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", code, http.StatusBadRequest)
+		}
+	})
+}
@@ -4,26 +4,36 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gosimple/slug"
 
 	"github.com/joybiswas007/blog/internal/database"
 )
 
-// registerPostRoutes handles CRUD for posts, protected by auth.
+// registerPostRoutes handles CRUD for posts. Every route signs the request in: the CRUD
+// routes with requireScope, so personal access tokens can use them, the rest with CheckJWT.
 func registerPostRoutes(rg *gin.RouterGroup, s *APIV1Service) {
 	posts := rg.Group("posts")
-	posts.GET("", s.postsHandler)
-	posts.GET(":id", s.getPostByIDHandler)
-	posts.POST("", s.createPostHandler)
-	posts.PATCH(":id", s.updatePostHandler)
-	posts.DELETE(":id", s.deletePostHandler)
-	posts.POST("publish/:id", s.publishDraftHandler)
+	posts.GET("", s.requireScope(scopePostsRead), s.postsHandler)
+	posts.GET(":id", s.requireScope(scopePostsRead), s.getPostByIDHandler)
+	posts.POST("", s.requireScope(scopePostsWrite), s.createPostHandler)
+	posts.PATCH(":id", s.requireScope(scopePostsWrite), s.CanEditPost(), s.updatePostHandler)
+	posts.DELETE(":id", s.requireScope(scopePostsPublish), s.RequireRole(publisherRoles...), s.deletePostHandler)
+	posts.POST("publish/:id", s.requireScope(scopePostsPublish), s.RequireRole(publisherRoles...), s.publishDraftHandler)
+	posts.POST("unpublish/:id", s.requireScope(scopePostsPublish), s.RequireRole(publisherRoles...), s.unpublishPostHandler)
+	posts.PUT(":id/visibility", s.requireScope(scopePostsPublish), s.RequireRole(publisherRoles...), s.setVisibilityHandler)
+
+	signedIn := posts.Group("", s.CheckJWT())
+	registerRevisionRoutes(signedIn, s)
+	registerAutosaveRoutes(signedIn, s)
+	registerLockRoutes(signedIn, s)
+	registerPostPasswordRoutes(signedIn, s)
+	registerPreviewAdminRoutes(signedIn, s)
 }
 
 func (s *APIV1Service) postsHandler(c *gin.Context) {
-	posts, _, totalPost, err := getPosts(c, s)
+	posts, _, totalPost, err := getPosts(c, s, true)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -49,16 +59,21 @@ func (s *APIV1Service) getPostByIDHandler(c *gin.Context) {
 	}
 
 	// Respond with the fetched post
+	c.Header("ETag", etag(post.Version))
 	c.JSON(http.StatusOK, gin.H{"post": post})
 }
 
 func (s *APIV1Service) createPostHandler(c *gin.Context) {
 	var input struct {
-		Title       string   `json:"title" binding:"required,max=255"`
-		Description string   `json:"description,omitempty"`
-		Content     string   `json:"content" binding:"required"`
-		IsPublished bool     `json:"is_published"`
-		Tags        []string `json:"tags" binding:"required"`
+		Title       string     `json:"title" binding:"required,max=255"`
+		Slug        string     `json:"slug" binding:"omitempty,max=255"`
+		Description string     `json:"description,omitempty"`
+		Content     string     `json:"content" binding:"required"`
+		IsPublished bool       `json:"is_published"`
+		Visibility  string     `json:"visibility" binding:"omitempty,oneof=draft unlisted private published"`
+		PublishAt   *time.Time `json:"publish_at"`
+		Tags        []string   `json:"tags" binding:"required"`
+		AuthorIDs   []int64    `json:"author_ids" binding:"omitempty,dive,gt=0"` // Byline order, defaults to the current user
 	}
 
 	// Parse the post data from the request body
@@ -68,14 +83,45 @@ func (s *APIV1Service) createPostHandler(c *gin.Context) {
 		return
 	}
 
-	exists, err := s.db.Posts.Exists(c.Request.Context(), slug.Make(input.Title))
+	// visibility wins over the older is_published flag.
+	if input.Visibility == "" {
+		input.Visibility = database.VisibilityDraft
+		if input.IsPublished {
+			input.Visibility = database.VisibilityPublished
+		}
+	}
+
+	// Contributors and authors hand in drafts, publishing is up to admins and editors.
+	if (input.Visibility != database.VisibilityDraft || input.PublishAt != nil) && !canPublish(c.GetString("role")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	if input.PublishAt != nil {
+		if input.Visibility != database.VisibilityDraft {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only drafts can be scheduled."})
+			return
+		}
+		if !input.PublishAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at must be in the future"})
+			return
+		}
+	}
+
+	postSlug, err := makeSlug(input.Slug, input.Title)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exists, err := s.db.Posts.Exists(c.Request.Context(), postSlug, 0)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	if exists {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "A post with this Title already exists. Please choose a unique Title."})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A post with this slug already exists. Please choose a unique slug."})
 		return
 	}
 
@@ -112,17 +158,18 @@ func (s *APIV1Service) createPostHandler(c *gin.Context) {
 	}
 	post := &database.Post{
 		Title:       input.Title,
-		Slug:        slug.Make(input.Title),
+		Slug:        postSlug,
 		UserID:      int64(uid),
 		Description: &input.Description,
 		Content:     input.Content,
-		IsPublished: input.IsPublished,
+		Visibility:  input.Visibility,
+		PublishAt:   input.PublishAt,
 	}
 
 	// Create the post in the database
-	postID, err := s.db.Posts.Create(c.Request.Context(), post)
+	postID, err := s.db.Posts.Create(c.Request.Context(), post, input.AuthorIDs...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(authorsErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -155,25 +202,84 @@ func (s *APIV1Service) updatePostHandler(c *gin.Context) {
 		return
 	}
 
-	var post *database.Post
+	var input struct {
+		database.Post
+		CancelSchedule bool    `json:"cancel_schedule"`                          // Drop the publish_at time of a scheduled draft
+		AuthorIDs      []int64 `json:"author_ids" binding:"omitempty,dive,gt=0"` // Replaces the authors in byline order if set
+	}
 
 	// Parse json response from the body
-	err = c.ShouldBindJSON(&post)
+	err = c.ShouldBindJSON(&input)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	post := &input.Post
 	post.ID = pid
 
-	updatedSlug := slug.Make(post.Title)
-	post.Slug = updatedSlug
+	// If-Match takes precedence over a version sent in the body.
+	version, precondition := ifMatchVersion(c)
+	if precondition {
+		post.Version = version
+	}
+
+	// Validate schedule changes before touching the post.
+	if post.PublishAt != nil || input.CancelSchedule {
+		if !canPublish(c.GetString("role")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrNotEnoughPerm})
+			return
+		}
+		current, err := s.db.Posts.Get(c.Request.Context(), pid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if current.Visibility != database.VisibilityDraft {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only drafts can be scheduled."})
+			return
+		}
+		if post.PublishAt != nil && !post.PublishAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at must be in the future"})
+			return
+		}
+	}
 
-	err = s.db.Posts.Update(c.Request.Context(), post)
+	// Keep a custom slug if one was sent, otherwise derive it from the title.
+	post.Slug, err = makeSlug(post.Slug, post.Title)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	taken, err := s.db.Posts.Exists(c.Request.Context(), post.Slug, pid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if taken {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A post with this slug already exists. Please choose a unique slug."})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	// The schedule is saved with the rest of the post, or not at all.
+	schedule := post.PublishAt != nil || input.CancelSchedule
+	if input.CancelSchedule {
+		post.PublishAt = nil
+	}
+
+	err = s.db.Posts.Update(c.Request.Context(), post, int64(uid), schedule, input.AuthorIDs...)
+	if err != nil {
+		c.JSON(updateErrorStatus(err, precondition), gin.H{"error": err.Error()})
+		return
+	}
+
 	// delete all other cache key
 	deleteCacheKey(s.redisStore)
 
@@ -183,12 +289,21 @@ func (s *APIV1Service) updatePostHandler(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(updatedPost.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Post updated successfully!",
 		"post":    updatedPost,
 	})
 }
 
+// authorsErrorStatus maps errors from saving post authors to HTTP status codes.
+func authorsErrorStatus(err error) int {
+	if errors.Is(err, database.ErrUnknownAuthor) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func (s *APIV1Service) deletePostHandler(c *gin.Context) {
 	pid, err := getIDFromParam(c)
 	if err != nil {
@@ -204,7 +319,7 @@ func (s *APIV1Service) deletePostHandler(c *gin.Context) {
 
 	deleteCacheKey(s.redisStore)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully!"})
+	c.JSON(http.StatusOK, gin.H{"message": "Post moved to trash!"})
 }
 
 func (s *APIV1Service) publishDraftHandler(c *gin.Context) {
@@ -236,3 +351,65 @@ func (s *APIV1Service) publishDraftHandler(c *gin.Context) {
 	// If the post is already published
 	c.JSON(http.StatusOK, gin.H{"message": "Post is already published"})
 }
+
+// unpublishPostHandler takes a post down by turning it back into a draft.
+func (s *APIV1Service) unpublishPostHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Posts.SetVisibility(c.Request.Context(), pid, database.VisibilityDraft)
+	if err != nil {
+		c.JSON(visibilityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post unpublished successfully"})
+}
+
+// setVisibilityHandler moves a post to any visibility state: draft, unlisted, private or published.
+func (s *APIV1Service) setVisibilityHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		Visibility string `json:"visibility" binding:"required,oneof=draft unlisted private published"`
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	err = s.db.Posts.SetVisibility(c.Request.Context(), pid, input.Visibility)
+	if err != nil {
+		c.JSON(visibilityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	post, err := s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post visibility updated successfully!", "post": post})
+}
+
+// visibilityErrorStatus maps a visibility change error to an HTTP status code.
+func visibilityErrorStatus(err error) int {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"maps"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// The authors are replaced in the transaction of the update, a failure leaves the post
+// and its version alone.
+func TestUpdatePostAuthors(t *testing.T) {
+	tests := []struct {
+		name      string
+		authorErr error
+		status    int
+	}{
+		{name: "authors replaced", status: http.StatusOK},
+		{name: "unknown author", authorErr: &pgconn.PgError{Code: "23503"}, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+
+			mock.ExpectQuery(`SELECT EXISTS`).WithArgs("title", 5).
+				WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT version FROM blog_posts`).WithArgs(5).
+				WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(1))
+			mock.ExpectQuery(`SELECT slug FROM blog_posts`).WithArgs(5).
+				WillReturnRows(pgxmock.NewRows([]string{"slug"}).AddRow("title"))
+			mock.ExpectExec(`INSERT INTO post_revisions`).WithArgs(5).
+				WillReturnResult(pgxmock.NewResult("INSERT", 0))
+			mock.ExpectQuery(`UPDATE blog_posts`).WithArgs("Title", pgxmock.AnyArg(), "Content", "title", 5).
+				WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(2))
+			mock.ExpectExec(`DELETE FROM post_authors`).WithArgs(5).
+				WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			mock.ExpectExec(`INSERT INTO post_authors`).WithArgs(5, int64(2), 0).
+				WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			insert := mock.ExpectExec(`INSERT INTO post_authors`).WithArgs(5, int64(3), 1)
+			if tt.authorErr != nil {
+				insert.WillReturnError(tt.authorErr)
+				mock.ExpectRollback()
+			} else {
+				insert.WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectExec(`INSERT INTO post_revisions`).WithArgs(5, int64(1)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectCommit()
+				expectPost(mock, &database.Post{ID: 5, Title: "Title", Content: "Content", Slug: "title", Version: 2})
+			}
+
+			body := gin.H{"title": "Title", "content": "Content", "author_ids": []int64{2, 3}}
+			w := serve(t, http.MethodPatch, "/posts/:id", "/posts/5", body, nil, signedIn(1), s.updatePostHandler)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+// The schedule is set in the transaction of the update, the post only gets one new
+// version and isn't changed at all if it stopped being a draft in the meantime.
+func TestUpdatePostSchedule(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	asEditor := func(c *gin.Context) { c.Set("role", database.RoleEditor) }
+
+	tests := []struct {
+		name     string
+		body     gin.H
+		want     *time.Time
+		notDraft bool
+		status   int
+	}{
+		{name: "scheduled", body: gin.H{"publish_at": publishAt}, want: &publishAt, status: http.StatusOK},
+		{name: "schedule cancelled", body: gin.H{"cancel_schedule": true}, status: http.StatusOK},
+		{name: "published meanwhile", body: gin.H{"publish_at": publishAt}, want: &publishAt, notDraft: true, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			draft := &database.Post{ID: 5, Title: "Title", Content: "Content", Slug: "title", Visibility: database.VisibilityDraft, Version: 1}
+
+			expectPost(mock, draft)
+			mock.ExpectQuery(`SELECT EXISTS`).WithArgs("title", 5).
+				WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT version FROM blog_posts`).WithArgs(5).
+				WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(1))
+			mock.ExpectQuery(`SELECT slug FROM blog_posts`).WithArgs(5).
+				WillReturnRows(pgxmock.NewRows([]string{"slug"}).AddRow("title"))
+			mock.ExpectExec(`INSERT INTO post_revisions`).WithArgs(5).
+				WillReturnResult(pgxmock.NewResult("INSERT", 0))
+			mock.ExpectQuery(`UPDATE blog_posts`).WithArgs("Title", pgxmock.AnyArg(), "Content", "title", 5).
+				WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(2))
+			schedule := mock.ExpectExec(`UPDATE blog_posts SET publish_at`).WithArgs(tt.want, 5)
+			if tt.notDraft {
+				schedule.WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				mock.ExpectRollback()
+			} else {
+				schedule.WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				mock.ExpectExec(`INSERT INTO post_revisions`).WithArgs(5, int64(1)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectCommit()
+				expectPost(mock, &database.Post{ID: 5, Title: "Title", Content: "Content", Slug: "title", Visibility: database.VisibilityDraft, Version: 2})
+			}
+
+			body := gin.H{"title": "Title", "content": "Content"}
+			maps.Copy(body, tt.body)
+			w := serve(t, http.MethodPatch, "/posts/:id", "/posts/5", body, nil, signedIn(1), asEditor, s.updatePostHandler)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
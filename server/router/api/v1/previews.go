@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// defaultPreviewTTL is how many hours a preview link works when no lifetime is given.
+const defaultPreviewTTL = 72
+
+// registerPreviewRoutes handles public read-only previews of unpublished posts.
+// Previews are never cached and don't count as views.
+func registerPreviewRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	previews := rg.Group("previews")
+	previews.GET(":token", s.previewHandler)
+}
+
+// registerPreviewAdminRoutes handles the preview links of posts, protected by auth.
+func registerPreviewAdminRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	previews := rg.Group(":id/previews", s.CanEditPost())
+	previews.GET("", s.previewsHandler)
+	previews.POST("", s.createPreviewHandler)
+	previews.DELETE(":preview_id", s.revokePreviewHandler)
+}
+
+func (s *APIV1Service) previewHandler(c *gin.Context) {
+	// Previews are private links, keep them out of shared caches and search engines.
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Robots-Tag", "noindex")
+
+	pid, err := s.db.Previews.GetPostID(c.Request.Context(), pkg.HashToken(c.Param("token")))
+	if err != nil {
+		c.JSON(previewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	post, err := s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(previewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"post": post, "preview": true})
+}
+
+func (s *APIV1Service) previewsHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previews, err := s.db.Previews.GetAll(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"previews": previews})
+}
+
+// createPreviewHandler mints a preview link for an unpublished post. The token is only
+// returned here, it can't be looked up again later.
+func (s *APIV1Service) createPreviewHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		ExpiresIn int `json:"expires_in" binding:"omitempty,min=1,max=720"` // Hours until the link expires, at most 30 days
+	}
+
+	// The body is optional, an empty one gets the default lifetime.
+	if c.Request.ContentLength > 0 {
+		err = c.ShouldBindJSON(&input)
+		if err != nil {
+			inputValidationErrors(c, err)
+			return
+		}
+	}
+
+	if input.ExpiresIn == 0 {
+		input.ExpiresIn = defaultPreviewTTL
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	post, err := s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(previewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if post.IsPublished {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Post is already published"})
+		return
+	}
+
+	token, err := pkg.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(input.ExpiresIn) * time.Hour)
+
+	preview, err := s.db.Previews.Create(c.Request.Context(), pid, int64(uid), pkg.HashToken(token), expiresAt)
+	if err != nil {
+		c.JSON(previewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Preview link created successfully!",
+		"token":   token,
+		"url":     fmt.Sprintf("%s/preview/%s", s.config.Blog.URL, token),
+		"preview": preview,
+	})
+}
+
+func (s *APIV1Service) revokePreviewHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previewID, err := getIntParam(c, "preview_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Previews.Delete(c.Request.Context(), pid, previewID)
+	if err != nil {
+		c.JSON(previewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Preview link revoked successfully!"})
+}
+
+// previewErrorStatus maps a preview error to an HTTP status code.
+func previewErrorStatus(err error) int {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
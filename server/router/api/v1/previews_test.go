@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+func TestCreatePreview(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility string
+		status     int
+	}{
+		{name: "draft", visibility: database.VisibilityDraft, status: http.StatusCreated},
+		{name: "private", visibility: database.VisibilityPrivate, status: http.StatusCreated},
+		{name: "published", visibility: database.VisibilityPublished, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			expectPost(mock, &database.Post{ID: 3, UserID: 1, Slug: "draft", Visibility: tt.visibility})
+			if tt.status == http.StatusCreated {
+				now := time.Now()
+				mock.ExpectQuery(`INSERT INTO post_previews`).
+					WithArgs(3, int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "post_id", "expires_at", "created_at"}).
+						AddRow(1, 3, now.Add(time.Hour), now))
+			}
+
+			w := serve(t, http.MethodPost, "/posts/:id/previews", "/posts/3/previews", nil, nil,
+				signedIn(1), s.createPreviewHandler)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	t.Run("unpublished post", func(t *testing.T) {
+		s, mock := newTestService(t)
+		mock.ExpectQuery(`FROM post_previews pp`).WithArgs(pkg.HashToken("token")).
+			WillReturnRows(pgxmock.NewRows([]string{"post_id"}).AddRow(3))
+		expectPost(mock, &database.Post{ID: 3, Slug: "draft", Visibility: database.VisibilityDraft})
+
+		w := serve(t, http.MethodGet, "/previews/:token", "/previews/token", nil, nil, s.previewHandler)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "private, no-store" {
+			t.Errorf("Cache-Control = %q", got)
+		}
+	})
+
+	// Published since the link was made, or unknown, revoked or expired: the query finds nothing.
+	t.Run("published or unknown", func(t *testing.T) {
+		s, mock := newTestService(t)
+		mock.ExpectQuery(`FROM post_previews pp`).WithArgs(pkg.HashToken("token")).
+			WillReturnRows(pgxmock.NewRows([]string{"post_id"}))
+
+		w := serve(t, http.MethodGet, "/previews/:token", "/previews/token", nil, nil, s.previewHandler)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusNotFound, w.Body)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	cache "github.com/chenyahui/gin-cache"
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// postTokenHeader carries the token returned by the unlock endpoint of a password protected post.
+const postTokenHeader = "X-Post-Token"
+
+// postTokenTTL is how long an unlocked post stays readable before the password is asked again.
+const postTokenTTL = time.Hour
+
+// protectedContent replaces the content of locked posts in public responses.
+const protectedContent = "This post is password protected."
+
+// Wrong passwords are limited per address like logins, and per post so guessing from many
+// addresses doesn't get around the limit either.
+const (
+	postUnlockMaxAttempts = 100       // Attempts on a post from every address within postUnlockWindow
+	postUnlockWindow      = time.Hour // Window attempts on a post are counted over
+)
+
+// registerPostPasswordRoutes handles the passwords of protected posts, protected by auth.
+func registerPostPasswordRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	rg.PUT(":id/password", s.RequireRole(publisherRoles...), s.setPostPasswordHandler)
+	rg.DELETE(":id/password", s.RequireRole(publisherRoles...), s.removePostPasswordHandler)
+}
+
+// unlockPostHandler checks the password of a protected post and returns a short-lived
+// token. Sending the token in the X-Post-Token header reveals the content of the post,
+// until the token expires or the password changes.
+func (s *APIV1Service) unlockPostHandler(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	post, err := s.db.Posts.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		c.JSON(postPasswordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !post.IsProtected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Post is not password protected"})
+		return
+	}
+
+	now := time.Now()
+	attempt, err := s.db.UnlockAttempts.Count(c.Request.Context(), post.ID, c.ClientIP(),
+		s.config.MaxLoginAttempts, now.Add(time.Hour*time.Duration(s.config.BanDuration)), now.Add(-postUnlockWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if attempt.BannedUntil != nil && now.Before(*attempt.BannedUntil) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Too many bad unlock attempts. Ban expires in " + time.Until(*attempt.BannedUntil).Round(time.Second).String(),
+		})
+		return
+	}
+
+	if attempt.PostAttempts >= postUnlockMaxAttempts {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Too many bad unlock attempts on this post, try again later."})
+		return
+	}
+
+	ok, err := s.db.Posts.MatchPassword(c.Request.Context(), post.ID, input.Password)
+	if err != nil {
+		c.JSON(postPasswordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Incorrect password"})
+		return
+	}
+
+	err = s.db.UnlockAttempts.Reset(c.Request.Context(), post.ID, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	expiresAt := now.Add(postTokenTTL)
+	claims := map[string]any{
+		"post_id":          post.ID,
+		"password_version": post.PasswordVersion,
+		"type":             TokenTypePost,
+		"exp":              expiresAt.Unix(),
+	}
+
+	token, err := s.generateJWT(c.Request.Context(), claims, s.config.JWT.Secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "expires_at": expiresAt})
+}
+
+func (s *APIV1Service) setPostPasswordHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		// bcrypt ignores everything after 72 bytes.
+		Password string `json:"password" binding:"required,min=8,max=72"`
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	err = s.db.Posts.SetPassword(c.Request.Context(), pid, input.Password)
+	if err != nil {
+		c.JSON(postPasswordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post password set successfully!"})
+}
+
+func (s *APIV1Service) removePostPasswordHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Posts.RemovePassword(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(postPasswordErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post password removed successfully!"})
+}
+
+// postUnlocked reports whether the request carries a valid unlock token for the current
+// password of post.
+func (s *APIV1Service) postUnlocked(c *gin.Context, post *database.Post) bool {
+	token := c.GetHeader(postTokenHeader)
+	if token == "" {
+		return false
+	}
+
+	claims, err := s.parseJWT(c.Request.Context(), token, s.config.JWT.Secret)
+	if err != nil {
+		return false
+	}
+
+	tokenType, ok := claims["type"].(string)
+	if !ok || tokenType != TokenTypePost {
+		return false
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok || time.Now().Unix() > int64(exp) {
+		return false
+	}
+
+	pid, ok := claims["post_id"].(float64)
+	if !ok || int(pid) != post.ID {
+		return false
+	}
+
+	version, ok := claims["password_version"].(float64)
+	return ok && int(version) == post.PasswordVersion
+}
+
+// redactProtected hides the content of a password protected post.
+func redactProtected(post *database.Post) {
+	if post != nil && post.IsProtected {
+		post.Content = ""
+	}
+}
+
+// cacheUnlessUnlocking caches responses by request URI, but skips the cache for
+// requests carrying an unlock token so unlocked content is never stored or served
+// from the shared cache.
+func cacheUnlessUnlocking(c *gin.Context) (bool, cache.Strategy) {
+	if c.GetHeader(postTokenHeader) != "" {
+		return false, cache.Strategy{}
+	}
+	return cache.CacheStrategyRequestURI(c)
+}
+
+// postPasswordErrorStatus maps a post password error to an HTTP status code.
+func postPasswordErrorStatus(err error) int {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,173 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pashagolub/pgxmock/v4"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+const testPostPassword = "correct horse"
+
+// expectPostBySlug expects GetBySlug to find a protected post with the given password version.
+func expectPostBySlug(mock pgxmock.PgxPoolIface, post *database.Post) {
+	now := time.Now()
+	rows := pgxmock.NewRows([]string{
+		"id", "author", "authors", "title", "description", "content", "slug", "is_published",
+		"visibility", "is_protected", "password_version", "status", "publish_at", "published_at",
+		"created_at", "updated_at", "version", "tags",
+	}).AddRow(
+		post.ID, "Ada", []database.Author{}, "Secret", nil, "hidden", post.Slug, true,
+		database.VisibilityPublished, post.IsProtected, post.PasswordVersion, database.StatusPublished, nil, &now,
+		now, now, 1, []string{},
+	)
+	mock.ExpectQuery(`FROM\s+blog_posts bp`).WithArgs(post.Slug).WillReturnRows(rows)
+}
+
+// expectUnlockAttempt expects an attempt to be counted and returns the given counts.
+func expectUnlockAttempt(mock pgxmock.PgxPoolIface, postID, attempts int, bannedUntil *time.Time, postAttempts int) {
+	rows := pgxmock.NewRows([]string{"attempts", "banned_until", "sum"}).
+		AddRow(attempts, bannedUntil, postAttempts)
+	mock.ExpectQuery(`INSERT INTO post_unlock_attempts`).
+		WithArgs(postID, "203.0.113.7", 3, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(rows)
+}
+
+func expectPostPassword(t *testing.T, mock pgxmock.PgxPoolIface, postID int) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPostPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	mock.ExpectQuery(`SELECT password_hash FROM blog_posts`).WithArgs(postID).
+		WillReturnRows(pgxmock.NewRows([]string{"password_hash"}).AddRow(hash))
+}
+
+func unlock(t *testing.T, s *APIV1Service, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serve(t, http.MethodPost, "/posts/:slug/unlock", "/posts/secret/unlock",
+		gin.H{"password": password}, nil, s.unlockPostHandler)
+}
+
+func TestUnlockPost(t *testing.T) {
+	post := &database.Post{ID: 7, Slug: "secret", IsProtected: true, PasswordVersion: 2}
+	banned := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		password string
+		expect   func(t *testing.T, mock pgxmock.PgxPoolIface)
+		status   int
+	}{
+		{
+			name:     "correct password",
+			password: testPostPassword,
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {
+				expectPostBySlug(mock, post)
+				expectUnlockAttempt(mock, post.ID, 1, nil, 0)
+				expectPostPassword(t, mock, post.ID)
+				mock.ExpectExec(`DELETE FROM post_unlock_attempts`).WithArgs(post.ID, "203.0.113.7").
+					WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:     "wrong password",
+			password: "wrong password",
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {
+				expectPostBySlug(mock, post)
+				expectUnlockAttempt(mock, post.ID, 2, nil, 5)
+				expectPostPassword(t, mock, post.ID)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:     "address banned",
+			password: testPostPassword,
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {
+				expectPostBySlug(mock, post)
+				expectUnlockAttempt(mock, post.ID, 4, &banned, 10)
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:     "too many attempts on the post",
+			password: testPostPassword,
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {
+				expectPostBySlug(mock, post)
+				expectUnlockAttempt(mock, post.ID, 1, nil, postUnlockMaxAttempts)
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:     "post not protected",
+			password: testPostPassword,
+			expect: func(t *testing.T, mock pgxmock.PgxPoolIface) {
+				expectPostBySlug(mock, &database.Post{ID: 7, Slug: "secret"})
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock := newTestService(t)
+			tt.expect(t, mock)
+
+			w := unlock(t, s, tt.password)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestPostUnlocked(t *testing.T) {
+	post := &database.Post{ID: 7, Slug: "secret", IsProtected: true, PasswordVersion: 2}
+
+	s, mock := newTestService(t)
+	expectPostBySlug(mock, post)
+	expectUnlockAttempt(mock, post.ID, 1, nil, 0)
+	expectPostPassword(t, mock, post.ID)
+	mock.ExpectExec(`DELETE FROM post_unlock_attempts`).WithArgs(post.ID, "203.0.113.7").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	w := unlock(t, s, testPostPassword)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unlock status = %d: %s", w.Code, w.Body)
+	}
+	token, _ := decode(t, w)["token"].(string)
+
+	tests := []struct {
+		name  string
+		token string
+		post  database.Post
+		want  bool
+	}{
+		{name: "valid token", token: token, post: *post, want: true},
+		{name: "no token", post: *post},
+		{name: "other post", token: token, post: database.Post{ID: 8, IsProtected: true, PasswordVersion: 2}},
+		{name: "password changed", token: token, post: database.Post{ID: 7, IsProtected: true, PasswordVersion: 3}},
+		{name: "garbage", token: "not.a.token", post: *post},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/posts/secret", nil)
+			if tt.token != "" {
+				c.Request.Header.Set(postTokenHeader, tt.token)
+			}
+
+			if got := s.postUnlocked(c, &tt.post); got != tt.want {
+				t.Errorf("postUnlocked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
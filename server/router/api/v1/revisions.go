@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// registerRevisionRoutes handles the revision history of posts, protected by auth.
+func registerRevisionRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	revisions := rg.Group(":id/revisions", s.CanEditPost())
+	revisions.GET("", s.revisionsHandler)
+	revisions.GET("diff", s.revisionDiffHandler)
+	revisions.GET(":revision_id", s.getRevisionHandler)
+	revisions.POST(":revision_id/restore", s.restoreRevisionHandler)
+}
+
+func (s *APIV1Service) revisionsHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, err := s.db.Revisions.GetAll(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+func (s *APIV1Service) getRevisionHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rid, err := getIntParam(c, "revision_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, err := s.db.Revisions.Get(c.Request.Context(), pid, rid)
+	if err != nil {
+		c.JSON(revisionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revision": revision})
+}
+
+// revisionDiffHandler returns a unified diff between the revisions given by the "from" and "to" query parameters.
+func (s *APIV1Service) revisionDiffHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fromID, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a valid revision id"})
+		return
+	}
+
+	toID, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a valid revision id"})
+		return
+	}
+
+	from, err := s.db.Revisions.Get(c.Request.Context(), pid, fromID)
+	if err != nil {
+		c.JSON(revisionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	to, err := s.db.Revisions.Get(c.Request.Context(), pid, toID)
+	if err != nil {
+		c.JSON(revisionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	diff := pkg.UnifiedDiff(
+		fmt.Sprintf("revision/%d", from.ID),
+		fmt.Sprintf("revision/%d", to.ID),
+		revisionText(from),
+		revisionText(to),
+	)
+
+	c.JSON(http.StatusOK, gin.H{"from": from.ID, "to": to.ID, "diff": diff})
+}
+
+// restoreRevisionHandler makes an old revision the current version of the post.
+// The restore itself is saved as a new revision, so it can be undone as well.
+func (s *APIV1Service) restoreRevisionHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rid, err := getIntParam(c, "revision_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, err := s.db.Revisions.Get(c.Request.Context(), pid, rid)
+	if err != nil {
+		c.JSON(revisionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	post := &database.Post{
+		ID:          pid,
+		Title:       revision.Title,
+		Description: revision.Description,
+		Content:     revision.Content,
+		Slug:        revision.Slug,
+		Tags:        revision.Tags,
+	}
+
+	err = s.db.Posts.Update(c.Request.Context(), post, int64(uid), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// delete all other cache key
+	deleteCacheKey(s.redisStore)
+
+	restoredPost, err := s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Post restored to revision %d", revision.ID),
+		"post":    restoredPost,
+	})
+}
+
+// revisionErrorStatus maps a revision lookup error to an HTTP status code.
+func revisionErrorStatus(err error) int {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// revisionText renders a revision as plain text so metadata changes show up in diffs.
+func revisionText(r *database.Revision) string {
+	var description string
+	if r.Description != nil {
+		description = *r.Description
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Title: %s\n", r.Title)
+	fmt.Fprintf(&sb, "Slug: %s\n", r.Slug)
+	fmt.Fprintf(&sb, "Description: %s\n", description)
+	fmt.Fprintf(&sb, "Tags: %s\n", strings.Join(r.Tags, ", "))
+	sb.WriteString("\n")
+	sb.WriteString(r.Content)
+
+	return sb.String()
+}
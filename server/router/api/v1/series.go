@@ -0,0 +1,236 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	cache "github.com/chenyahui/gin-cache"
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// registerSeriesRoutes handles public series display.
+func registerSeriesRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	series := rg.Group("series")
+	series.GET(":slug", cache.CacheByRequestURI(s.redisStore, 30*time.Minute), s.seriesBySlugHandler)
+}
+
+// registerSeriesAdminRoutes handles CRUD for series, protected by auth.
+func registerSeriesAdminRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	series := rg.Group("series", s.RequireRole(publisherRoles...))
+	series.GET("", s.seriesListHandler)
+	series.GET(":id", s.getSeriesHandler)
+	series.POST("", s.createSeriesHandler)
+	series.PATCH(":id", s.updateSeriesHandler)
+	series.DELETE(":id", s.deleteSeriesHandler)
+	series.PUT(":id/posts", s.setSeriesPostsHandler)
+}
+
+func (s *APIV1Service) seriesBySlugHandler(c *gin.Context) {
+	series, err := s.db.Series.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		c.JSON(seriesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series})
+}
+
+func (s *APIV1Service) seriesListHandler(c *gin.Context) {
+	series, err := s.db.Series.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series})
+}
+
+func (s *APIV1Service) getSeriesHandler(c *gin.Context) {
+	sid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := s.db.Series.Get(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(seriesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series})
+}
+
+func (s *APIV1Service) createSeriesHandler(c *gin.Context) {
+	var input struct {
+		Title       string  `json:"title" binding:"required,max=255"`
+		Slug        string  `json:"slug" binding:"omitempty,max=255"`
+		Description *string `json:"description"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	seriesSlug, err := makeSlug(input.Slug, input.Title)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series := &database.Series{
+		Title:       input.Title,
+		Slug:        seriesSlug,
+		Description: input.Description,
+	}
+
+	sid, err := s.db.Series.Create(c.Request.Context(), series)
+	if err != nil {
+		c.JSON(seriesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	createdSeries, err := s.db.Series.Get(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Series created successfully!", "series": createdSeries})
+}
+
+func (s *APIV1Service) updateSeriesHandler(c *gin.Context) {
+	sid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		Title       string  `json:"title" binding:"required,max=255"`
+		Slug        string  `json:"slug" binding:"omitempty,max=255"`
+		Description *string `json:"description"`
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	seriesSlug, err := makeSlug(input.Slug, input.Title)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series := &database.Series{
+		ID:          sid,
+		Title:       input.Title,
+		Slug:        seriesSlug,
+		Description: input.Description,
+	}
+
+	err = s.db.Series.Update(c.Request.Context(), series)
+	if err != nil {
+		c.JSON(seriesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// series info is embedded in cached post responses
+	deleteCacheKey(s.redisStore)
+
+	updatedSeries, err := s.db.Series.Get(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Series updated successfully!", "series": updatedSeries})
+}
+
+func (s *APIV1Service) deleteSeriesHandler(c *gin.Context) {
+	sid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Series.Delete(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(seriesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Series deleted successfully!"})
+}
+
+// setSeriesPostsHandler replaces the posts of a series. The order of post_ids is the reading order.
+func (s *APIV1Service) setSeriesPostsHandler(c *gin.Context) {
+	sid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		PostIDs []int `json:"post_ids" binding:"required"`
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	seen := make(map[int]bool, len(input.PostIDs))
+	for _, pid := range input.PostIDs {
+		if seen[pid] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "post_ids must not contain duplicates"})
+			return
+		}
+		seen[pid] = true
+	}
+
+	// Make sure the series exists before touching its posts.
+	_, err = s.db.Series.Get(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(seriesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Series.SetPosts(c.Request.Context(), sid, input.PostIDs)
+	if err != nil {
+		c.JSON(seriesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	updatedSeries, err := s.db.Series.Get(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Series posts updated successfully!", "series": updatedSeries})
+}
+
+// seriesErrorStatus maps a series model error to an HTTP status code.
+func seriesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, database.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrDuplicateSlug), errors.Is(err, database.ErrPostInAnotherSeries):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// registerSessionRoutes handles the sessions of the current user, protected by auth.
+func registerSessionRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	sessions := rg.Group("sessions")
+	sessions.GET("", s.sessionsHandler)
+	sessions.DELETE(":id", s.revokeSessionHandler)
+}
+
+// sessionsHandler lists where the current user is signed in, marking the session the
+// request came from.
+func (s *APIV1Service) sessionsHandler(c *gin.Context) {
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	sessions, err := s.db.Sessions.GetAll(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	current := int64(c.GetFloat64("session_id"))
+	for _, session := range sessions {
+		session.Current = session.ID == current
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// revokeSessionHandler signs the current user out of one of their sessions. Its access
+// tokens stop working right away.
+func (s *APIV1Service) revokeSessionHandler(c *gin.Context) {
+	sessionID, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	err = s.db.Sessions.Revoke(c.Request.Context(), int64(uid), int64(sessionID))
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserSessionRevoke, database.AuditTargetUser, int64(uid), map[string]any{"session_id": sessionID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully!"})
+}
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerTagAdminRoutes handles tag cleanup. The routes sign the request in with
+// requireScope, so personal access tokens can use them.
+func registerTagAdminRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	tags := rg.Group("tags")
+	tags.GET("", s.requireScope(scopeTagsAdmin), s.RequireRole(publisherRoles...), s.tagsHandler)
+	tags.DELETE(":id", s.requireScope(scopeTagsAdmin), s.RequireRole(publisherRoles...), s.deleteTagHandler)
+}
+
+// tagsHandler lists every tag with the number of posts using it, unused ones included.
+func (s *APIV1Service) tagsHandler(c *gin.Context) {
+	tags, err := s.db.Tags.GetAll(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// deleteTagHandler deletes a tag, removing it from the posts that use it.
+func (s *APIV1Service) deleteTagHandler(c *gin.Context) {
+	tagID, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Tags.Delete(c.Request.Context(), tagID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully!"})
+}
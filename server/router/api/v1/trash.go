@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// registerTrashRoutes handles trashed posts, protected by auth.
+func registerTrashRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	trash := rg.Group("trash", s.RequireRole(publisherRoles...))
+	trash.GET("", s.trashHandler)
+	trash.POST(":id/restore", s.restorePostHandler)
+	trash.DELETE(":id", s.purgePostHandler)
+}
+
+func (s *APIV1Service) trashHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	filter := database.Filter{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	posts, totalPost, err := s.db.Posts.GetTrash(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_post": totalPost,
+		"posts":      posts,
+	})
+}
+
+func (s *APIV1Service) restorePostHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Posts.Restore(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteCacheKey(s.redisStore)
+
+	post, err := s.db.Posts.Get(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post restored successfully!", "post": post})
+}
+
+// purgePostHandler permanently deletes a trashed post. Posts have to be trashed first.
+func (s *APIV1Service) purgePostHandler(c *gin.Context) {
+	pid, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Posts.Purge(c.Request.Context(), pid)
+	if err != nil {
+		c.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post deleted permanently!"})
+}
+
+// trashErrorStatus maps a trash operation error to an HTTP status code.
+func trashErrorStatus(err error) int {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,409 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at a time.
+const recoveryCodeCount = 10
+
+// mfaTokenTTL is how long the two-factor step of a login may take.
+const mfaTokenTTL = 5 * time.Minute
+
+// Ways a second factor can be proven.
+const (
+	secondFactorTOTP     = "totp"
+	secondFactorRecovery = "recovery_code"
+)
+
+// registerTwoFactorRoutes handles two-factor enrollment of the current user, protected by auth.
+func registerTwoFactorRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	twoFactor := rg.Group("me/2fa")
+	twoFactor.GET("", s.twoFactorStatusHandler)
+	twoFactor.POST("setup", s.setupTwoFactorHandler)
+	twoFactor.POST("enable", s.enableTwoFactorHandler)
+	twoFactor.POST("disable", s.disableTwoFactorHandler)
+	twoFactor.POST("recovery-codes", s.recoveryCodesHandler)
+}
+
+// loginTwoFactorHandler is the second step of a login for users with two-factor auth.
+// It exchanges the challenge token from loginHandler and a TOTP or recovery code for
+// the real tokens.
+func (s *APIV1Service) loginTwoFactorHandler(c *gin.Context) {
+	var input struct {
+		MFAToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	claims, err := s.parseJWT(c.Request.Context(), input.MFAToken, s.config.JWT.Secret)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrTokenInvalidOrExpired})
+		return
+	}
+
+	tokenType, ok := claims["type"].(string)
+	if !ok || tokenType != TokenTypeMFA {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidFormat})
+		return
+	}
+
+	uid, ok := claims["user_id"].(float64)
+	if !ok || uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	user, err := s.db.Users.GetByID(c.Request.Context(), int64(uid))
+	if err != nil || !user.TwoFactorEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrUnauthorized})
+		return
+	}
+	if user.DeactivatedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDeactivated})
+		return
+	}
+
+	attemptID, ok := s.checkLoginAttempt(c, user.ID)
+	if !ok {
+		return
+	}
+
+	method, err := s.verifySecondFactor(c.Request.Context(), user.ID, input.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if method == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect or already used code"})
+		return
+	}
+
+	if method == secondFactorRecovery {
+		s.audit(c, database.AuditUser2FARecovery, database.AuditTargetUser, user.ID, nil)
+	}
+
+	s.completeLogin(c, user.ID, attemptID)
+}
+
+func (s *APIV1Service) twoFactorStatusHandler(c *gin.Context) {
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	tf, err := s.db.TwoFactor.Get(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":             tf.Enabled(),
+		"enabled_at":          tf.EnabledAt,
+		"recovery_codes_left": tf.RecoveryCodes,
+	})
+}
+
+// setupTwoFactorHandler starts enrollment. It returns a new secret and the otpauth:// URI
+// to show as a QR code; two-factor auth is only turned on once enableTwoFactorHandler
+// gets a valid code for it.
+func (s *APIV1Service) setupTwoFactorHandler(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	user, ok := s.currentUserWithPassword(c, input.Password)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": database.ErrTwoFactorEnabled.Error()})
+		return
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.TwoFactor.SetSecret(c.Request.Context(), user.ID, secret)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Scan the QR code with your authenticator app, then confirm with a code.",
+		"secret":      secret,
+		"otpauth_uri": pkg.TOTPURI(s.config.Blog.Name, user.Email, secret),
+	})
+}
+
+// enableTwoFactorHandler confirms enrollment with a code from the authenticator app.
+// The recovery codes are only returned here, they can't be looked up again later.
+func (s *APIV1Service) enableTwoFactorHandler(c *gin.Context) {
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	tf, err := s.db.TwoFactor.Get(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if tf.Enabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": database.ErrTwoFactorEnabled.Error()})
+		return
+	}
+	if tf.Secret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start the two-factor setup first"})
+		return
+	}
+
+	step, ok := pkg.ValidateTOTP(tf.Secret, input.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect code"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.TwoFactor.Enable(c.Request.Context(), int64(uid), step, hashes)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUser2FAEnable, database.AuditTargetUser, int64(uid), nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled! Store the recovery codes somewhere safe.",
+		"recovery_codes": codes,
+	})
+}
+
+// disableTwoFactorHandler turns two-factor auth off. It asks for the password and a
+// current code, so a stolen access token alone can't remove the second factor.
+func (s *APIV1Service) disableTwoFactorHandler(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	user, ok := s.currentUserWithPassword(c, input.Password)
+	if !ok {
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication isn't enabled"})
+		return
+	}
+
+	method, err := s.verifySecondFactor(c.Request.Context(), user.ID, input.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if method == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect or already used code"})
+		return
+	}
+
+	err = s.db.TwoFactor.Disable(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUser2FADisable, database.AuditTargetUser, user.ID, map[string]any{"method": method})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled."})
+}
+
+// recoveryCodesHandler replaces all recovery codes of the current user with new ones.
+// Like disabling two-factor auth it asks for the password and a current code.
+func (s *APIV1Service) recoveryCodesHandler(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	user, ok := s.currentUserWithPassword(c, input.Password)
+	if !ok {
+		return
+	}
+
+	method, err := s.verifySecondFactor(c.Request.Context(), user.ID, input.Code)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if method == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect or already used code"})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.TwoFactor.ReplaceRecoveryCodes(c.Request.Context(), user.ID, hashes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUser2FACodes, database.AuditTargetUser, user.ID, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "New recovery codes generated, the old ones no longer work.",
+		"recovery_codes": codes,
+	})
+}
+
+// verifySecondFactor checks a TOTP or recovery code of a user with two-factor auth on
+// and uses it up. It returns how the factor was proven, or an empty string if the code
+// is wrong, replayed or two-factor auth is off.
+func (s *APIV1Service) verifySecondFactor(ctx context.Context, userID int64, code string) (string, error) {
+	tf, err := s.db.TwoFactor.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if !tf.Enabled() {
+		return "", nil
+	}
+
+	if step, ok := pkg.ValidateTOTP(tf.Secret, code, time.Now()); ok {
+		used, err := s.db.TwoFactor.UseStep(ctx, userID, step)
+		if err != nil || !used {
+			return "", err
+		}
+		return secondFactorTOTP, nil
+	}
+
+	used, err := s.db.TwoFactor.UseRecoveryCode(ctx, userID, pkg.HashToken(pkg.NormalizeRecoveryCode(code)))
+	if err != nil || !used {
+		return "", err
+	}
+	return secondFactorRecovery, nil
+}
+
+// currentUserWithPassword loads the authenticated user and checks their password, for
+// changes that need more than a valid access token. It writes the response and returns
+// false if the check fails.
+func (s *APIV1Service) currentUserWithPassword(c *gin.Context, password string) (*database.User, bool) {
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return nil, false
+	}
+
+	user, err := s.db.Users.GetByID(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	match, err := user.Password.Match(password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !match {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// newRecoveryCodes generates a fresh set of recovery codes along with the hashes to store.
+func newRecoveryCodes() ([]string, [][]byte, error) {
+	codes, err := pkg.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([][]byte, len(codes))
+	for i, code := range codes {
+		hashes[i] = pkg.HashToken(pkg.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// generateMFAToken returns the short-lived challenge token for the two-factor step of a login.
+func generateMFAToken(ctx context.Context, userID int64, s *APIV1Service) (string, error) {
+	claims := map[string]any{
+		"user_id": userID,
+		"type":    TokenTypeMFA,
+		"exp":     time.Now().Add(mfaTokenTTL).Unix(),
+	}
+	return s.generateJWT(ctx, claims, s.config.JWT.Secret)
+}
+
+// twoFactorErrorStatus maps a two-factor error to an HTTP status code.
+func twoFactorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, database.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrTwoFactorEnabled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
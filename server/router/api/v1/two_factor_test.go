@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+func TestRecoveryCodes(t *testing.T) {
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	enabledAt := time.Now()
+	step := pkg.TOTPStep(enabledAt)
+	code, err := pkg.TOTPCode(secret, step)
+	if err != nil {
+		t.Fatalf("TOTPCode: %v", err)
+	}
+
+	regenerate := func(t *testing.T, s *APIV1Service, password string) int {
+		t.Helper()
+		body := gin.H{"password": password, "code": code}
+		w := serve(t, http.MethodPost, "/auth/me/2fa/recovery-codes", "/auth/me/2fa/recovery-codes", body, nil,
+			signedIn(1), s.recoveryCodesHandler)
+		return w.Code
+	}
+
+	t.Run("password and code", func(t *testing.T) {
+		s, mock := newTestService(t)
+		expectUser(t, mock, 1, "password")
+		mock.ExpectQuery(`SELECT COALESCE\(u.totp_secret`).WithArgs(int64(1)).
+			WillReturnRows(pgxmock.NewRows([]string{"secret", "enabled_at", "last_step", "count"}).
+				AddRow(secret, &enabledAt, step-1, 10))
+		mock.ExpectExec(`SET totp_last_step`).WithArgs(int64(1), step).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM recovery_codes`).WithArgs(int64(1)).
+			WillReturnResult(pgxmock.NewResult("DELETE", 10))
+		mock.ExpectExec(`INSERT INTO recovery_codes`).WithArgs(int64(1), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", recoveryCodeCount))
+		mock.ExpectCommit()
+		expectAudit(mock, database.AuditUser2FACodes)
+
+		if status := regenerate(t, s, "password"); status != http.StatusOK {
+			t.Fatalf("status = %d, want %d", status, http.StatusOK)
+		}
+	})
+
+	// A stolen access token and a shoulder-surfed code aren't enough.
+	t.Run("wrong password", func(t *testing.T) {
+		s, mock := newTestService(t)
+		expectUser(t, mock, 1, "password")
+
+		if status := regenerate(t, s, "guess"); status != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d", status, http.StatusUnauthorized)
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+// registerUserRoutes handles user management, admins only.
+func registerUserRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	users := rg.Group("users", s.RequireRole(database.RoleAdmin))
+	users.GET("", s.usersHandler)
+	users.GET(":id", s.getUserHandler)
+	users.POST("", s.createUserHandler)
+	users.PATCH(":id", s.updateUserHandler)
+	users.POST(":id/deactivate", s.deactivateUserHandler(true))
+	users.POST(":id/activate", s.deactivateUserHandler(false))
+	users.DELETE(":id", s.deleteUserHandler)
+}
+
+func (s *APIV1Service) usersHandler(c *gin.Context) {
+	limit, offset := getPagination(c)
+
+	users, totalUser, err := s.db.Users.GetAll(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total_user": totalUser, "users": users})
+}
+
+func (s *APIV1Service) getUserHandler(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.db.Users.GetByID(c.Request.Context(), int64(id))
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// createUserHandler creates a user with a password set by the admin.
+// Invitations let new users choose their own password instead.
+func (s *APIV1Service) createUserHandler(c *gin.Context) {
+	var input struct {
+		Name     string `json:"name" binding:"required,max=255"`
+		Email    string `json:"email" binding:"required,email"`
+		Role     string `json:"role" binding:"omitempty,oneof=admin editor author contributor"`
+		Password string `json:"password" binding:"required,min=8,max=72"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	user := &database.User{
+		Name:  input.Name,
+		Email: input.Email,
+		Role:  input.Role,
+	}
+
+	err = user.Password.Set(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, err = s.db.Users.Insert(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserCreate, database.AuditTargetUser, user.ID, map[string]any{"email": user.Email, "role": user.Role})
+
+	c.JSON(http.StatusCreated, gin.H{"message": "User created successfully!", "user": user})
+}
+
+// updateUserHandler changes the name, email or role of a user. Fields left out are kept.
+func (s *APIV1Service) updateUserHandler(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		Name  *string `json:"name" binding:"omitempty,min=1,max=255"`
+		Email *string `json:"email" binding:"omitempty,email"`
+		Role  *string `json:"role" binding:"omitempty,oneof=admin editor author contributor"`
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	// Admins can't demote themselves, someone has to be left to manage users.
+	if input.Role != nil && *input.Role != database.RoleAdmin && isCurrentUser(c, int64(id)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't change your own role."})
+		return
+	}
+
+	user, err := s.db.Users.GetByID(c.Request.Context(), int64(id))
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil {
+		user.Name = *input.Name
+	}
+	if input.Email != nil {
+		user.Email = *input.Email
+	}
+	if input.Role != nil {
+		user.Role = *input.Role
+	}
+
+	err = s.db.Users.Update(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserUpdate, database.AuditTargetUser, user.ID, map[string]any{"name": user.Name, "email": user.Email, "role": user.Role})
+
+	// Bylines are cached.
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User updated successfully!", "user": user})
+}
+
+// deactivateUserHandler locks a user out, or lets them back in if deactivate is false.
+func (s *APIV1Service) deactivateUserHandler(deactivate bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := getIDFromParam(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if deactivate && isCurrentUser(c, int64(id)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You can't deactivate yourself."})
+			return
+		}
+
+		err = s.db.Users.SetDeactivated(c.Request.Context(), int64(id), deactivate)
+		if err != nil {
+			c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		action, message := database.AuditUserActivate, "User activated successfully!"
+		if deactivate {
+			action, message = database.AuditUserDeactivate, "User deactivated successfully!"
+		}
+		s.audit(c, action, database.AuditTargetUser, int64(id), nil)
+
+		c.JSON(http.StatusOK, gin.H{"message": message})
+	}
+}
+
+// deleteUserHandler permanently deletes a user. Users who own posts can only be
+// deleted if reassign_to names the user taking them over.
+func (s *APIV1Service) deleteUserHandler(c *gin.Context) {
+	id, err := getIDFromParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if isCurrentUser(c, int64(id)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't delete yourself."})
+		return
+	}
+
+	var reassignTo int64
+	if v := c.Query("reassign_to"); v != "" {
+		reassignTo, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || reassignTo < 1 || reassignTo == int64(id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reassign_to must be the ID of another user"})
+			return
+		}
+	}
+
+	err = s.db.Users.Delete(c.Request.Context(), int64(id), reassignTo)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrUserHasPosts):
+			c.JSON(http.StatusConflict, gin.H{"error": "This user still owns posts. Deactivate them instead, or pass reassign_to."})
+		default:
+			c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	var details map[string]any
+	if reassignTo != 0 {
+		details = map[string]any{"reassign_to": reassignTo}
+	}
+	s.audit(c, database.AuditUserDelete, database.AuditTargetUser, int64(id), details)
+
+	deleteCacheKey(s.redisStore)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully!"})
+}
+
+// updateMyPasswordHandler changes the password of the current user after checking the current one.
+// The other sessions and the personal access tokens of the user are revoked.
+func (s *APIV1Service) updateMyPasswordHandler(c *gin.Context) {
+	var input struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=8,max=72"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	user, err := s.db.Users.GetByID(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(authorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := user.Password.Match(input.CurrentPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !match {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	err = user.Password.Set(input.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = s.db.Users.UpdatePassword(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Whoever knew the old password may be signed in elsewhere, sign them out but keep
+	// this session.
+	err = s.db.Sessions.RevokeAll(c.Request.Context(), user.ID, int64(c.GetFloat64("session_id")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	revoked, err := s.db.AccessTokens.DeleteAll(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserPasswordChange, database.AuditTargetUser, user.ID, map[string]any{"access_tokens_revoked": revoked})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully!"})
+}
+
+// isCurrentUser reports whether userID belongs to the authenticated user.
+func isCurrentUser(c *gin.Context, userID int64) bool {
+	return int64(c.GetFloat64("user_id")) == userID
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/joybiswas007/blog/internal/database"
+)
+
+func TestCreateUser(t *testing.T) {
+	body := gin.H{"name": "Jane Doe", "email": "jane@example.com", "password": "password123"}
+	insertArgs := []any{"Jane Doe", "jane@example.com", pgxmock.AnyArg(), "author", "jane-doe", true}
+	handleTaken := &pgconn.PgError{Code: "23505", ConstraintName: "users_handle_key"}
+
+	t.Run("derives the handle from the name", func(t *testing.T) {
+		s, mock := newTestService(t)
+		mock.ExpectQuery(`INSERT INTO users`).WithArgs(insertArgs...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "handle"}).AddRow(int64(7), "jane-doe-7"))
+		expectAudit(mock, database.AuditUserCreate)
+
+		w := serve(t, http.MethodPost, "/users", "/users", body, nil, s.createUserHandler)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+		}
+		user := decode(t, w)["user"].(map[string]any)
+		if user["handle"] != "jane-doe-7" {
+			t.Errorf("handle = %v, want jane-doe-7", user["handle"])
+		}
+	})
+
+	t.Run("retries when the derived handle was just taken", func(t *testing.T) {
+		s, mock := newTestService(t)
+		mock.ExpectQuery(`INSERT INTO users`).WithArgs(insertArgs...).WillReturnError(handleTaken)
+		mock.ExpectQuery(`INSERT INTO users`).WithArgs(insertArgs...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "handle"}).AddRow(int64(8), "jane-doe-8"))
+		expectAudit(mock, database.AuditUserCreate)
+
+		w := serve(t, http.MethodPost, "/users", "/users", body, nil, s.createUserHandler)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+		}
+	})
+}
+
+func TestUpdateMyPassword(t *testing.T) {
+	inSession := func(c *gin.Context) { c.Set("session_id", float64(9)) }
+
+	t.Run("revokes other sessions and access tokens", func(t *testing.T) {
+		s, mock := newTestService(t)
+		expectUser(t, mock, 1, "old password")
+		mock.ExpectExec(`UPDATE users`).WithArgs(pgxmock.AnyArg(), int64(1)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`UPDATE sessions SET revoked_at`).WithArgs(int64(1), int64(9)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		mock.ExpectExec(`DELETE FROM personal_access_tokens`).WithArgs(int64(1)).
+			WillReturnResult(pgxmock.NewResult("DELETE", 3))
+		expectAudit(mock, database.AuditUserPasswordChange)
+
+		body := gin.H{"current_password": "old password", "new_password": "new password"}
+		w := serve(t, http.MethodPut, "/auth/me/password", "/auth/me/password", body, nil,
+			signedIn(1), inSession, s.updateMyPasswordHandler)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+		}
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		s, mock := newTestService(t)
+		expectUser(t, mock, 1, "old password")
+
+		body := gin.H{"current_password": "guess", "new_password": "new password"}
+		w := serve(t, http.MethodPut, "/auth/me/password", "/auth/me/password", body, nil,
+			signedIn(1), inSession, s.updateMyPasswordHandler)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+		}
+	})
+}
@@ -15,11 +15,13 @@ import (
 	"github.com/gin-contrib/cors"
 	ginexp "github.com/gin-contrib/expvar"
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
 	sloggin "github.com/samber/slog-gin"
 
 	"github.com/joybiswas007/blog/config"
 	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/keyring"
+	"github.com/joybiswas007/blog/internal/mailer"
+	"github.com/joybiswas007/blog/internal/passkey"
 	"github.com/joybiswas007/blog/server/router/frontend"
 )
 
@@ -29,14 +31,19 @@ type APIV1Service struct {
 	logger     *slog.Logger
 	db         database.Models
 	redisStore *persist.RedisStore
+	mailer     mailer.Mailer
+	passkeys   *passkey.Service
+	keys       *keyring.Keyring
 }
 
 // NewAPIV1Service creates a new API v1 service instance.
-func NewAPIV1Service(cfg *config.Config, logger *slog.Logger, db database.Models) *APIV1Service {
+func NewAPIV1Service(cfg *config.Config, logger *slog.Logger, db database.Models, redisStore *persist.RedisStore) *APIV1Service {
 	return &APIV1Service{
-		config: cfg,
-		logger: logger,
-		db:     db,
+		config:     cfg,
+		logger:     logger,
+		db:         db,
+		redisStore: redisStore,
+		keys:       keyring.New(db.SigningKeys.GetAll, cfg.JWT.KeySecret, legacyTokenGrace(cfg), logger),
 	}
 }
 
@@ -49,16 +56,6 @@ func (s *APIV1Service) RegisterRoutes() http.Handler {
 	}
 	r.Use(s.RateLimiter())
 
-	redisOptions := &redis.Options{
-		Addr:     s.config.Redis.Address,
-		Username: s.config.Redis.Username,
-		Password: s.config.Redis.Password,
-	}
-
-	redisStore := persist.NewRedisStore(redis.NewClient(redisOptions))
-
-	s.redisStore = redisStore
-
 	// create a 3 second context for redis.
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -70,6 +67,16 @@ func (s *APIV1Service) RegisterRoutes() http.Handler {
 
 	fmt.Println("Redis ping:", redisPing)
 
+	s.mailer, err = mailer.New(s.config.Mail, s.config.Blog.URL, s.logger)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	s.passkeys, err = passkey.New(s.config.WebAuthn, s.config.Blog.Name, s.config.Blog.URL)
+	if err != nil {
+		log.Panic(err)
+	}
+
 	r.Use(sloggin.NewWithConfig(s.logger, sloggin.Config{
 		WithUserAgent:    true,
 		DefaultLevel:     slog.LevelInfo,
@@ -89,7 +96,8 @@ func (s *APIV1Service) RegisterRoutes() http.Handler {
 		r.Use(cors.New(cors.Config{
 			AllowOrigins:     []string{"http://localhost:3001"},
 			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-			AllowHeaders:     []string{"Accept", "Authorization", "Content-Type"},
+			AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "If-Match", postTokenHeader, csrfHeader},
+			ExposeHeaders:    []string{"ETag"},
 			AllowCredentials: true,
 		}))
 
@@ -97,6 +105,7 @@ func (s *APIV1Service) RegisterRoutes() http.Handler {
 	}
 	r.GET("rss.xml", s.rssHandler)
 	r.GET("sitemap.xml", s.siteMapHandler)
+	r.GET(".well-known/jwks.json", s.jwksHandler)
 
 	// Register routes for each module.
 	api := r.Group("api")
@@ -109,10 +118,20 @@ func (s *APIV1Service) RegisterRoutes() http.Handler {
 
 	// api routes
 	registerBlogRoutes(v1, s)
+	registerSeriesRoutes(v1, s)
+	registerAuthorRoutes(v1, s)
+	registerPreviewRoutes(v1, s)
+	registerInvitationRoutes(v1, s)
 	registerAuthRoutes(v1, s)
 
 	// server the frontend
-	frontend.Serve(r)
+	frontend.Serve(r, s.db.Posts.GetRedirectSlug)
 
 	return r
 }
+
+// legacyTokenGrace is the longest a token signed with the JWT secrets lives, they keep
+// verifying that long after the first signing key was generated.
+func legacyTokenGrace(cfg *config.Config) time.Duration {
+	return time.Hour * time.Duration(max(cfg.JWT.Exp, cfg.JWT.RefExp))
+}
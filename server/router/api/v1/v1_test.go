@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/chenyahui/gin-cache/persist"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/pashagolub/pgxmock/v4"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/joybiswas007/blog/config"
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/keyring"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestService returns a service whose models run against a mocked database and whose
+// cache is an in-memory Redis. No signing key is stored, so tokens are signed with the
+// JWT secret.
+func newTestService(t *testing.T) (*APIV1Service, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock.NewPool: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("database expectations: %v", err)
+		}
+		mock.Close()
+	})
+
+	cfg := &config.Config{
+		JWT:              config.JWT{Secret: "access-secret", RefSecret: "refresh-secret", Exp: 1, RefExp: 24},
+		MaxLoginAttempts: 3,
+		BanDuration:      1,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	noKeys := func(context.Context) ([]*database.SigningKey, error) { return nil, nil }
+
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	s := &APIV1Service{
+		config:     cfg,
+		logger:     logger,
+		db:         database.NewModels(mock),
+		redisStore: persist.NewRedisStore(rdb),
+		keys:       keyring.New(noKeys, "key-secret", 0, logger),
+	}
+	return s, mock
+}
+
+// serve sends a request with a JSON body, if any, through h mounted on method and path.
+func serve(t *testing.T, method, path, target string, body any, header http.Header, h ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	req.RemoteAddr = "203.0.113.7:1234"
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	r := gin.New()
+	r.Handle(method, path, h...)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals the JSON body of a response.
+func decode(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+// signedIn stands in for the auth middleware, signing the request in as userID.
+func signedIn(userID int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_id", float64(userID))
+	}
+}
+
+// expectPost expects Posts.Get to find post.
+func expectPost(mock pgxmock.PgxPoolIface, post *database.Post) {
+	now := time.Now()
+	rows := pgxmock.NewRows([]string{
+		"id", "user_id", "author", "authors", "title", "description", "content", "slug",
+		"is_published", "visibility", "is_protected", "status", "publish_at", "published_at",
+		"created_at", "updated_at", "version", "tags",
+	}).AddRow(
+		post.ID, post.UserID, "Ada", []database.Author{}, post.Title, nil, post.Content, post.Slug,
+		post.Visibility == database.VisibilityPublished, post.Visibility, post.IsProtected, post.Visibility, nil, nil,
+		now, now, post.Version, post.Tags,
+	)
+	mock.ExpectQuery(`WHERE\s+bp.id = \$1`).WithArgs(post.ID).WillReturnRows(rows)
+}
+
+// expectUser expects Users.GetByID to find an author with the given password.
+func expectUser(t *testing.T, mock pgxmock.PgxPoolIface, userID int64, password string) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+
+	now := time.Now()
+	mock.ExpectQuery(`FROM users\s+WHERE id = \$1`).WithArgs(userID).WillReturnRows(
+		pgxmock.NewRows([]string{
+			"id", "name", "email", "password_hash", "role", "handle", "bio", "avatar_url", "links",
+			"deactivated_at", "totp_enabled", "created_at", "updated_at",
+		}).AddRow(
+			userID, "Ada", "ada@example.com", hash, database.RoleAuthor, "ada", "", "", []database.Link{},
+			nil, false, now, now,
+		))
+}
+
+// expectAudit expects an audit log entry for action.
+func expectAudit(mock pgxmock.PgxPoolIface, action string) {
+	mock.ExpectQuery(`INSERT INTO audit_logs`).
+		WithArgs(pgxmock.AnyArg(), action, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(1), time.Now()))
+}
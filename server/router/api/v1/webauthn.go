@@ -0,0 +1,327 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/joybiswas007/blog/internal/database"
+	"github.com/joybiswas007/blog/internal/passkey"
+	"github.com/joybiswas007/blog/pkg"
+)
+
+// webauthnSessionPrefix prefixes the Redis keys of pending passkey ceremonies.
+const webauthnSessionPrefix = "webauthn:session:"
+
+// errWebAuthnSession is returned when a ceremony is finished with an unknown, expired or
+// already used session, or one that was started for another user.
+var errWebAuthnSession = errors.New("passkey session expired, please try again")
+
+// webauthnSession is a pending passkey ceremony. UserID is zero for logins, where the
+// user is only known once the browser answers.
+type webauthnSession struct {
+	UserID  int64                `json:"user_id"`
+	Session webauthn.SessionData `json:"session"`
+}
+
+// registerWebAuthnRoutes handles passkey login, which is public.
+func registerWebAuthnRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	login := rg.Group("webauthn/login")
+	login.POST("begin", s.beginPasskeyLoginHandler)
+	login.POST("finish", s.finishPasskeyLoginHandler)
+}
+
+// registerWebAuthnAdminRoutes handles the passkeys of the current user, protected by auth.
+func registerWebAuthnAdminRoutes(rg *gin.RouterGroup, s *APIV1Service) {
+	wa := rg.Group("webauthn")
+	wa.POST("register/begin", s.beginPasskeyRegistrationHandler)
+	wa.POST("register/finish", s.finishPasskeyRegistrationHandler)
+	wa.GET("credentials", s.passkeysHandler)
+	wa.DELETE("credentials/:id", s.deletePasskeyHandler)
+}
+
+func (s *APIV1Service) beginPasskeyLoginHandler(c *gin.Context) {
+	options, session, err := s.passkeys.BeginLogin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := s.saveWebAuthnSession(c.Request.Context(), &webauthnSession{Session: *session})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session_id": sessionID, "options": options})
+}
+
+// finishPasskeyLoginHandler checks the browser's answer to beginPasskeyLoginHandler and
+// signs the user in. Passkeys verify the user on the device, so no TOTP code is asked for.
+func (s *APIV1Service) finishPasskeyLoginHandler(c *gin.Context) {
+	var input struct {
+		SessionID  string          `json:"session_id" binding:"required"`
+		Credential json.RawMessage `json:"credential" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	ws, err := s.takeWebAuthnSession(c.Request.Context(), input.SessionID)
+	if err == nil && ws.UserID != 0 {
+		err = errWebAuthnSession
+	}
+	if err != nil {
+		c.JSON(webauthnErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var deactivated bool
+	load := func(userID int64) (*passkey.User, error) {
+		u, err := s.db.Users.GetByID(c.Request.Context(), userID)
+		if err != nil {
+			return nil, err
+		}
+		deactivated = u.DeactivatedAt != nil
+
+		return s.passkeyUser(c.Request.Context(), u)
+	}
+
+	user, pk, err := s.passkeys.FinishLogin(ws.Session, input.Credential, load)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if deactivated {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDeactivated})
+		return
+	}
+
+	err = s.db.Passkeys.UpdateUsage(c.Request.Context(), pk)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, err := generateTokens(c, user.ID, s)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.respondWithTokens(c, http.StatusOK, gin.H{"message": "Login successful!"}, accessToken, refreshToken)
+}
+
+// beginPasskeyRegistrationHandler starts adding a passkey to the current user. Like
+// two-factor setup it asks for the password, so a stolen access token can't be turned
+// into a permanent way in.
+func (s *APIV1Service) beginPasskeyRegistrationHandler(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	u, ok := s.currentUserWithPassword(c, input.Password)
+	if !ok {
+		return
+	}
+
+	user, err := s.passkeyUser(c.Request.Context(), u)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	options, session, err := s.passkeys.BeginRegistration(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := s.saveWebAuthnSession(c.Request.Context(), &webauthnSession{UserID: u.ID, Session: *session})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session_id": sessionID, "options": options})
+}
+
+func (s *APIV1Service) finishPasskeyRegistrationHandler(c *gin.Context) {
+	var input struct {
+		SessionID  string          `json:"session_id" binding:"required"`
+		Name       string          `json:"name" binding:"max=100"`
+		Credential json.RawMessage `json:"credential" binding:"required"`
+	}
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		inputValidationErrors(c, err)
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	ws, err := s.takeWebAuthnSession(c.Request.Context(), input.SessionID)
+	if err == nil && ws.UserID != int64(uid) {
+		err = errWebAuthnSession
+	}
+	if err != nil {
+		c.JSON(webauthnErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	u, err := s.db.Users.GetByID(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(webauthnErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.passkeyUser(c.Request.Context(), u)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	pk, err := s.passkeys.FinishRegistration(user, ws.Session, input.Credential)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pk.Name = input.Name
+	if pk.Name == "" {
+		pk.Name = "Passkey"
+	}
+
+	err = s.db.Passkeys.Insert(c.Request.Context(), pk)
+	if err != nil {
+		c.JSON(webauthnErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserPasskeyAdd, database.AuditTargetUser, u.ID, map[string]any{"passkey_id": pk.ID, "name": pk.Name})
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Passkey added successfully!", "passkey": pk})
+}
+
+func (s *APIV1Service) passkeysHandler(c *gin.Context) {
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	passkeys, err := s.db.Passkeys.GetAll(c.Request.Context(), int64(uid))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"passkeys": passkeys})
+}
+
+func (s *APIV1Service) deletePasskeyHandler(c *gin.Context) {
+	passkeyID, err := getIntParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid := c.GetFloat64("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrNotEnoughPerm})
+		return
+	}
+
+	err = s.db.Passkeys.Delete(c.Request.Context(), int64(uid), int64(passkeyID))
+	if err != nil {
+		c.JSON(webauthnErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.audit(c, database.AuditUserPasskeyRemove, database.AuditTargetUser, int64(uid), map[string]any{"passkey_id": passkeyID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey removed successfully!"})
+}
+
+// passkeyUser loads the passkeys of u for a ceremony.
+func (s *APIV1Service) passkeyUser(ctx context.Context, u *database.User) (*passkey.User, error) {
+	passkeys, err := s.db.Passkeys.GetAll(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passkey.User{ID: u.ID, Name: u.Name, Email: u.Email, Passkeys: passkeys}, nil
+}
+
+// saveWebAuthnSession keeps a pending ceremony in Redis until it's finished or expires,
+// and returns the ID the browser sends back with its answer.
+func (s *APIV1Service) saveWebAuthnSession(ctx context.Context, ws *webauthnSession) (string, error) {
+	sessionID, err := pkg.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(ws)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.redisStore.RedisClient.Set(ctx, webauthnSessionPrefix+sessionID, data, passkey.SessionTTL).Err()
+	if err != nil {
+		return "", err
+	}
+
+	return sessionID, nil
+}
+
+// takeWebAuthnSession loads and deletes a pending ceremony, so each challenge is only
+// answered once.
+func (s *APIV1Service) takeWebAuthnSession(ctx context.Context, sessionID string) (*webauthnSession, error) {
+	data, err := s.redisStore.RedisClient.GetDel(ctx, webauthnSessionPrefix+sessionID).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errWebAuthnSession
+		}
+		return nil, err
+	}
+
+	var ws webauthnSession
+	err = json.Unmarshal(data, &ws)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ws, nil
+}
+
+// webauthnErrorStatus maps a passkey error to an HTTP status code.
+func webauthnErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errWebAuthnSession):
+		return http.StatusBadRequest
+	case errors.Is(err, database.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,6 +2,7 @@
 package frontend
 
 import (
+	"context"
 	"embed"
 	"log"
 	"net/http"
@@ -17,13 +18,23 @@ import (
 //go:embed "dist"
 var embeddedFiles embed.FS
 
+// SlugResolver returns the current slug of a post that used to live under oldSlug.
+// It returns an error if oldSlug doesn't belong to a renamed post.
+type SlugResolver func(ctx context.Context, oldSlug string) (string, error)
+
 // Serve sets up the frontend routes to serve embedded static files.
-func Serve(app *gin.Engine) {
+// Requests for renamed posts are permanently redirected to their current slug.
+func Serve(app *gin.Engine, resolveSlug SlugResolver) {
 	distFS := getFileSystem("dist")
 	app.Use(static.Serve("/", distFS))
 
 	app.NoRoute(func(c *gin.Context) {
 		if !strings.HasPrefix(c.Request.RequestURI, "/api") {
+			if target, ok := postRedirect(c, resolveSlug); ok {
+				c.Redirect(http.StatusMovedPermanently, target)
+				return
+			}
+
 			index, err := distFS.Open("index.html")
 			if err != nil {
 				log.Fatal(err)
@@ -35,6 +46,26 @@ func Serve(app *gin.Engine) {
 	})
 }
 
+// postRedirect checks whether the request targets a post under a retired slug
+// and returns the URL of the post's current location.
+func postRedirect(c *gin.Context, resolveSlug SlugResolver) (string, bool) {
+	oldSlug, ok := strings.CutPrefix(c.Request.URL.Path, "/posts/")
+	if !ok || oldSlug == "" || strings.Contains(oldSlug, "/") {
+		return "", false
+	}
+
+	slug, err := resolveSlug(c.Request.Context(), oldSlug)
+	if err != nil || slug == oldSlug {
+		return "", false
+	}
+
+	target := "/posts/" + slug
+	if c.Request.URL.RawQuery != "" {
+		target += "?" + c.Request.URL.RawQuery
+	}
+	return target, true
+}
+
 func getFileSystem(path string) static.ServeFileSystem {
 	fs, err := static.EmbedFolder(embeddedFiles, path)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/chenyahui/gin-cache/persist"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/joybiswas007/blog/config"
@@ -22,8 +23,9 @@ type Server struct {
 	logger *slog.Logger
 }
 
-// NewServer creates and configures a new HTTP server instance.
-func NewServer(db *pgxpool.Pool, cfg *config.Config, logger *slog.Logger) *http.Server {
+// NewServer creates and configures a new HTTP server instance. Responses are cached in
+// redisStore.
+func NewServer(db *pgxpool.Pool, redisStore *persist.RedisStore, cfg *config.Config, logger *slog.Logger) *http.Server {
 	NewServer := &Server{
 		port:   cfg.Port,
 		config: cfg,
@@ -31,7 +33,7 @@ func NewServer(db *pgxpool.Pool, cfg *config.Config, logger *slog.Logger) *http.
 		logger: logger,
 	}
 
-	v1Server := v1.NewAPIV1Service(NewServer.config, NewServer.logger, NewServer.db)
+	v1Server := v1.NewAPIV1Service(NewServer.config, NewServer.logger, NewServer.db, redisStore)
 
 	// Declare Server config.
 	server := &http.Server{
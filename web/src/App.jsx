@@ -7,9 +7,13 @@ const Posts = lazy(() => import("@/pages/Posts"));
 const Home = lazy(() => import("@/pages/Home"));
 const Post = lazy(() => import("@/pages/Post"));
 const Tags = lazy(() => import("@/pages/Tags"));
+const Author = lazy(() => import("@/pages/Author"));
 const Archives = lazy(() => import("@/pages/Archives"));
 const ArchiveByYear = lazy(() => import("@/pages/ArchiveByYear"));
 const Login = lazy(() => import("@/pages/Login"));
+const AcceptInvite = lazy(() => import("@/pages/AcceptInvite"));
+const ForgotPassword = lazy(() => import("@/pages/ForgotPassword"));
+const ResetPassword = lazy(() => import("@/pages/ResetPassword"));
 const Drafts = lazy(() => import("@/pages/Drafts"));
 const CreatePost = lazy(() => import("@/pages/CreatePost"));
 const EditPost = lazy(() => import("@/pages/EditPost"));
@@ -33,10 +37,15 @@ const App = () => {
             <Route path="/" element={<Home />} />
             <Route path="/posts" element={<Posts />} />
             <Route path="/posts/:slug" element={<Post />} />
+            <Route path="/preview/:token" element={<Post preview />} />
             <Route path="/tags" element={<Tags />} />
+            <Route path="/authors/:handle" element={<Author />} />
             <Route path="/archives" element={<Archives />} />
             <Route path="/archives/:year" element={<ArchiveByYear />} />
             <Route path="/login" element={<Login />} />
+            <Route path="/invite/:token" element={<AcceptInvite />} />
+            <Route path="/forgot-password" element={<ForgotPassword />} />
+            <Route path="/reset-password/:token" element={<ResetPassword />} />
             <Route element={<PrivateRoute />}>
               <Route path="/auth/drafts" element={<Drafts />} />
               <Route path="/auth/posts/create" element={<CreatePost />} />
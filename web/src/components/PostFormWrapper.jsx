@@ -1,4 +1,4 @@
-import { useState, useEffect } from "react";
+import { useState, useEffect, useRef } from "react";
 import { useNavigate, Link, useParams } from "react-router-dom";
 import { FiSend } from "react-icons/fi";
 import {
@@ -8,6 +8,20 @@ import {
 } from "@/components/PostForm";
 import api from "@/services/api";
 
+// How long the editor waits after the last change before autosaving, in ms.
+const AUTOSAVE_DELAY = 5000;
+
+// How often the edit lock is refreshed while the editor is open, in ms.
+const LOCK_REFRESH_INTERVAL = 60000;
+
+const toPayload = formData => ({
+  ...formData,
+  tags: formData.tags
+    .split(",")
+    .map(tag => tag.trim())
+    .filter(Boolean)
+});
+
 const PostFormWrapper = ({ mode = "create" }) => {
   const { id } = useParams();
   const navigate = useNavigate();
@@ -21,6 +35,11 @@ const PostFormWrapper = ({ mode = "create" }) => {
   const [fetching, setFetching] = useState(mode === "edit");
   const [error, setError] = useState(null);
   const [isPublished, setIsPublished] = useState(false);
+  const [autosavedAt, setAutosavedAt] = useState(null);
+  const [version, setVersion] = useState(null);
+  const [lockWarning, setLockWarning] = useState(null);
+  // Last form state stored on the server, to skip autosaves without changes.
+  const lastSaved = useRef(null);
 
   useEffect(() => {
     if (mode !== "edit") return;
@@ -30,13 +49,37 @@ const PostFormWrapper = ({ mode = "create" }) => {
         const response = await api.get(`/auth/posts/${id}`);
         const post = response.data.post;
         if (post) {
-          setFormData({
+          let data = {
             title: post.title,
             description: post.description || "",
             tags: post.tags?.join(", ") || "",
             content: post.content || ""
-          });
+          };
+          lastSaved.current = JSON.stringify(data);
+
+          const autosave = await api
+            .get(`/auth/posts/${id}/autosave`)
+            .then(res => res.data.autosave)
+            .catch(() => null);
+          if (autosave) {
+            const savedAt = new Date(autosave.updated_at).toLocaleString();
+            if (window.confirm(`Restore unsaved changes from ${savedAt}?`)) {
+              data = {
+                title: autosave.title,
+                description: autosave.description || "",
+                tags: autosave.tags?.join(", ") || "",
+                content: autosave.content
+              };
+              lastSaved.current = JSON.stringify(data);
+              setAutosavedAt(autosave.updated_at);
+            } else {
+              await api.delete(`/auth/posts/${id}/autosave`).catch(() => {});
+            }
+          }
+
+          setFormData(data);
           setIsPublished(post.is_published);
+          setVersion(post.version);
         }
       } catch (err) {
         setError(err.response?.data?.error || "Failed to fetch post");
@@ -48,6 +91,53 @@ const PostFormWrapper = ({ mode = "create" }) => {
     fetchPost();
   }, [id, mode]);
 
+  // Hold the edit lock while the editor is open so others get warned.
+  useEffect(() => {
+    if (mode !== "edit") return;
+
+    const acquireLock = async () => {
+      try {
+        await api.post(`/auth/posts/${id}/lock`);
+        setLockWarning(null);
+      } catch (err) {
+        if (err.response?.status === 409) {
+          setLockWarning(err.response.data.error);
+        }
+      }
+    };
+
+    acquireLock();
+    const interval = setInterval(acquireLock, LOCK_REFRESH_INTERVAL);
+
+    return () => {
+      clearInterval(interval);
+      api.delete(`/auth/posts/${id}/lock`).catch(() => {});
+    };
+  }, [id, mode]);
+
+  // Autosave edits of existing posts without touching the live version.
+  useEffect(() => {
+    if (mode !== "edit" || fetching || lastSaved.current === null) return;
+
+    const current = JSON.stringify(formData);
+    if (current === lastSaved.current) return;
+
+    const timer = setTimeout(async () => {
+      try {
+        const response = await api.put(
+          `/auth/posts/${id}/autosave`,
+          toPayload(formData)
+        );
+        lastSaved.current = current;
+        setAutosavedAt(response.data.autosave.updated_at);
+      } catch {
+        // Keep editing, the next change retries.
+      }
+    }, AUTOSAVE_DELAY);
+
+    return () => clearTimeout(timer);
+  }, [formData, mode, fetching, id]);
+
   const validateForm = () => {
     if (!formData.title || formData.title.length < 3) {
       setError("Title must be at least 3 characters long");
@@ -90,9 +180,11 @@ const PostFormWrapper = ({ mode = "create" }) => {
           await api.post(`/auth/posts/publish/${id}`);
           setIsPublished(true);
         } else {
-          await api.patch(`/auth/posts/${id}`, {
-            ...formData,
-            tags: formData.tags.split(",").map(tag => tag.trim())
+          // Store the latest edits in the buffer and promote it to the post.
+          await api.put(`/auth/posts/${id}/autosave`, toPayload(formData));
+          // Refuse to overwrite changes saved by someone else in the meantime.
+          await api.post(`/auth/posts/${id}/autosave/apply`, null, {
+            headers: version ? { "If-Match": `"${version}"` } : {}
           });
         }
       }
@@ -100,7 +192,11 @@ const PostFormWrapper = ({ mode = "create" }) => {
       navigate("/", { state: { success: true } });
     } catch (err) {
       const responseError = err.response?.data;
-      if (responseError?.errors && Array.isArray(responseError.errors)) {
+      if (err.response?.status === 412) {
+        setError(
+          "This post was changed by someone else. Your edits are autosaved, reload to see their changes."
+        );
+      } else if (responseError?.errors && Array.isArray(responseError.errors)) {
         setError(responseError.errors.map(e => Object.values(e)[0]).join(", "));
       } else if (responseError?.error) {
         setError(responseError.error);
@@ -135,8 +231,19 @@ const PostFormWrapper = ({ mode = "create" }) => {
                 ? "Make changes to your post below"
                 : "Fill in the details below to create a new post"}
             </p>
+            {autosavedAt && (
+              <p className="text-xs mt-1 font-mono text-[#5c6370]">
+                Autosaved at {new Date(autosavedAt).toLocaleTimeString()}
+              </p>
+            )}
           </div>
 
+          {lockWarning && (
+            <p className="px-3 py-2 rounded text-sm font-sans bg-[#2c313a] text-[#e5c07b] border border-[#e5c07b]">
+              {lockWarning}. Your changes may conflict with theirs.
+            </p>
+          )}
+
           <ErrorMessage error={error} />
 
           <PostFormFields
@@ -3,7 +3,7 @@ import { Link, useNavigate } from "react-router-dom";
 import remarkGfm from "remark-gfm";
 import { BsFileText } from "react-icons/bs";
 import { FiEdit2, FiTrash2 } from "react-icons/fi";
-import { getAuthTokens } from "@/utils/auth";
+import { isSignedIn } from "@/utils/auth";
 import api from "@/services/api";
 
 import { CalculateReadTime, TruncateText } from "@/utils/helpers";
@@ -21,8 +21,7 @@ const PostItem = ({
 }) => {
   const navigate = useNavigate();
   const tagLinkParams = { limit, offset: 0, order_by: orderBy, sort };
-  const { access_token } = getAuthTokens();
-  const isAuthenticated = !!access_token;
+  const isAuthenticated = isSignedIn();
 
   const handleEdit = (e) => {
     e.preventDefault();
@@ -34,7 +33,7 @@ const PostItem = ({
     e.preventDefault();
     e.stopPropagation();
 
-    if (window.confirm(`Are you sure you want to delete "${post.title}"?`)) {
+    if (window.confirm(`Move "${post.title}" to the trash?`)) {
       try {
         await api.delete(`/auth/posts/${post.id}`);
         if (onDelete) onDelete(post.id);
@@ -60,7 +59,7 @@ const PostItem = ({
 
             {/* Meta info */}
             <div className="flex items-center gap-2 mb-2 text-[11px] font-mono text-[var(--color-text-secondary)]">
-              <span>{formatDate(post.created_at)}</span>
+              <span>{formatDate(post.published_at || post.created_at)}</span>
               <span>·</span>
               <span>{CalculateReadTime(post.content)}</span>
             </div>
@@ -169,7 +168,7 @@ const NoPostsFound = ({ tag, buildQueryString, limit }) => {
   const allPostsLink = buildQueryString({
     limit,
     offset: 0,
-    order_by: "created_at",
+    order_by: "published_at",
     sort: "DESC",
     tag: undefined
   });
@@ -14,7 +14,8 @@ import {
 } from "react-icons/bs";
 import { FiGithub, FiLinkedin, FiMail, FiLogOut } from "react-icons/fi";
 import { FaXTwitter } from "react-icons/fa6";
-import { getAuthTokens, clearAuthTokens } from "@/utils/auth";
+import api from "@/services/api";
+import { isSignedIn, clearAuthTokens } from "@/utils/auth";
 
 const NAV_ITEMS = [
   { label: "Home", to: "/", icon: <BsHouseDoor /> },
@@ -60,8 +61,7 @@ const Sidebar = () => {
 
   useEffect(() => {
     const checkAuth = () => {
-      const { access_token } = getAuthTokens();
-      setIsAuthenticated(!!access_token);
+      setIsAuthenticated(isSignedIn());
     };
     checkAuth();
     // Listen for storage events to update auth state across tabs/windows
@@ -88,7 +88,13 @@ const Sidebar = () => {
     }
   }, [location.pathname, isMobile]);
 
-  const handleLogout = () => {
+  const handleLogout = async () => {
+    // Revoke the refresh token, signing out locally doesn't wait for it to work.
+    try {
+      await api.post("/auth/logout");
+    } catch {
+      // The session may have expired already.
+    }
     clearAuthTokens();
     setIsAuthenticated(false);
     navigate("/");
@@ -2,8 +2,8 @@ import { Link } from "react-router-dom";
 import { BsFilter, BsX } from "react-icons/bs";
 
 const SORT_OPTIONS = [
-  { orderBy: "created_at", sort: "DESC", label: "Newest" },
-  { orderBy: "created_at", sort: "ASC", label: "Oldest" },
+  { orderBy: "published_at", sort: "DESC", label: "Newest" },
+  { orderBy: "published_at", sort: "ASC", label: "Oldest" },
   { orderBy: "title", sort: "ASC", label: "A-Z" }
 ];
 
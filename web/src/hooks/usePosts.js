@@ -4,8 +4,8 @@ import api from "@/services/api";
 
 const DEFAULT_LIMIT = 10;
 const SORT_OPTIONS = [
-  { orderBy: "created_at", sort: "DESC", label: "Newest" },
-  { orderBy: "created_at", sort: "ASC", label: "Oldest" },
+  { orderBy: "published_at", sort: "DESC", label: "Newest" },
+  { orderBy: "published_at", sort: "ASC", label: "Oldest" },
   { orderBy: "title", sort: "ASC", label: "A-Z" }
 ];
 
@@ -20,7 +20,7 @@ export const usePosts = () => {
 
   const limit = Number(query.limit) || DEFAULT_LIMIT;
   const offset = Number(query.offset) || 0;
-  const orderBy = query.order_by || "created_at";
+  const orderBy = query.order_by || "published_at";
   const sort = query.sort || "DESC";
   const tag = query.tag || "";
 
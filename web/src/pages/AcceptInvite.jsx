@@ -0,0 +1,135 @@
+import { useEffect, useState } from "react";
+import { useNavigate, useParams } from "react-router-dom";
+import { FiUser, FiLock, FiUserPlus } from "react-icons/fi";
+import api from "@/services/api";
+import { setAuthTokens } from "@/utils/auth";
+
+const inputClass =
+  "w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-input-bg)] text-[var(--color-text-primary)] border border-[var(--color-input-border)] transition-all focus:outline-none focus:border-[var(--color-accent-primary)] focus:shadow-[0_0_0_3px_rgba(0,122,204,0.1)] placeholder:text-[var(--color-text-muted)]";
+
+const labelClass =
+  "flex items-center gap-2 text-sm font-medium font-sans text-[var(--color-text-secondary)]";
+
+const AcceptInvite = () => {
+  const { token } = useParams();
+  const [invitation, setInvitation] = useState(null);
+  const [name, setName] = useState("");
+  const [password, setPassword] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState("");
+  const navigate = useNavigate();
+
+  useEffect(() => {
+    api
+      .get(`/invitations/${token}`)
+      .then(response => setInvitation(response.data.invitation))
+      .catch(() =>
+        setError("This invitation is invalid, expired or was already used.")
+      );
+  }, [token]);
+
+  const handleAccept = async e => {
+    e.preventDefault();
+    setLoading(true);
+    setError("");
+
+    try {
+      const response = await api.post(`/invitations/${token}/accept`, {
+        name,
+        password
+      });
+      const { access_token, refresh_token } = response.data;
+      setAuthTokens({ access_token, refresh_token });
+      navigate("/");
+    } catch (err) {
+      setError(
+        err.response?.data?.error ||
+          "Could not accept the invitation. Please try again."
+      );
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <>
+      <title>Accept invitation</title>
+      <div className="flex items-center justify-center min-h-full py-8">
+        <div className="w-full max-w-md space-y-0">
+          {/* Header */}
+          <div className="text-center space-y-2 p-6 rounded-t bg-[var(--color-sidebar-bg)] border border-[var(--color-panel-border)] border-b-0">
+            <div className="w-12 h-12 mx-auto flex items-center justify-center rounded-full mb-2 bg-[var(--color-active-bg)] text-[var(--color-accent-primary)] text-xl">
+              <FiUserPlus />
+            </div>
+            <h1 className="text-2xl font-bold font-sans text-[var(--color-text-primary)]">
+              You're invited
+            </h1>
+            {invitation && (
+              <p className="text-sm font-mono text-[var(--color-text-secondary)]">
+                {invitation.email} · {invitation.role}
+              </p>
+            )}
+          </div>
+
+          {/* Form */}
+          <form
+            onSubmit={handleAccept}
+            className="space-y-4 p-6 bg-[var(--color-sidebar-bg)] border border-[var(--color-panel-border)] border-t-0 rounded-b"
+          >
+            {error && (
+              <div className="px-3 py-2 rounded text-sm font-sans bg-[rgba(220,38,38,0.1)] text-[#fca5a5] border border-[rgba(220,38,38,0.3)]">
+                {error}
+              </div>
+            )}
+
+            <div className="space-y-1.5">
+              <label htmlFor="name" className={labelClass}>
+                <FiUser className="text-sm" />
+                <span>Full Name</span>
+              </label>
+              <input
+                id="name"
+                name="name"
+                required
+                value={name}
+                onChange={e => setName(e.target.value)}
+                autoComplete="name"
+                className={inputClass}
+              />
+            </div>
+
+            <div className="space-y-1.5">
+              <label htmlFor="password" className={labelClass}>
+                <FiLock className="text-sm" />
+                <span>Password</span>
+              </label>
+              <input
+                id="password"
+                name="password"
+                type="password"
+                required
+                minLength={8}
+                value={password}
+                onChange={e => setPassword(e.target.value)}
+                autoComplete="new-password"
+                className={inputClass}
+                placeholder="At least 8 characters"
+              />
+            </div>
+
+            <button
+              type="submit"
+              disabled={loading || !invitation}
+              className="w-full inline-flex items-center justify-center gap-2 px-4 py-2.5 rounded transition-all text-sm font-medium font-sans bg-[var(--color-accent-primary)] text-white border border-[var(--color-accent-primary)] hover:bg-[var(--color-accent-hover)] hover:border-[var(--color-accent-hover)] disabled:opacity-50 disabled:cursor-not-allowed"
+            >
+              <FiUserPlus />
+              <span>{loading ? "Creating account..." : "Create Account"}</span>
+            </button>
+          </form>
+        </div>
+      </div>
+    </>
+  );
+};
+
+export default AcceptInvite;
@@ -44,7 +44,7 @@ const ArchiveByYear = () => {
   const groupPostsByMonth = posts => {
     const grouped = {};
     posts.forEach(post => {
-      const month = getMonthName(post.created_at);
+      const month = getMonthName(post.published_at);
       if (!grouped[month]) {
         grouped[month] = [];
       }
@@ -151,7 +151,7 @@ const ArchiveByYear = () => {
                     >
                       {/* Date */}
                       <span className="text-[11px] font-mono text-[#5c6370] min-w-[50px] shrink-0">
-                        {formatDate(post.created_at)}
+                        {formatDate(post.published_at)}
                       </span>
 
                       {/* Tree indent */}
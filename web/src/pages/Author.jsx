@@ -0,0 +1,169 @@
+import { useEffect, useState, useCallback } from "react";
+import { useParams, Link } from "react-router-dom";
+import { BsFileText, BsChevronRight, BsRss } from "react-icons/bs";
+import api from "@/services/api";
+import SEO from "@/components/SEO";
+
+const LIMIT = 10;
+
+const Author = () => {
+  const { handle } = useParams();
+  const [author, setAuthor] = useState(null);
+  const [posts, setPosts] = useState([]);
+  const [totalPost, setTotalPost] = useState(0);
+  const [offset, setOffset] = useState(0);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState("");
+
+  const fetchAuthor = useCallback(async () => {
+    try {
+      setLoading(true);
+      const response = await api.get(`/authors/${handle}`, {
+        params: { limit: LIMIT, offset }
+      });
+      setAuthor(response.data?.author);
+      setPosts(response.data?.posts || []);
+      setTotalPost(response.data?.total_post || 0);
+    } catch (error) {
+      setError(error.response?.data?.error || "Failed to fetch author");
+    } finally {
+      setLoading(false);
+    }
+  }, [handle, offset]);
+
+  const formatDate = dateString => {
+    return new Date(dateString).toLocaleDateString("en-US", {
+      year: "numeric",
+      month: "short",
+      day: "numeric"
+    });
+  };
+
+  useEffect(() => {
+    fetchAuthor();
+  }, [fetchAuthor]);
+
+  if (loading) {
+    return (
+      <div className="w-full max-w-3xl mx-auto">
+        <div className="h-24 mb-6 bg-[var(--color-hover-bg)] rounded animate-shimmer"></div>
+        <div className="space-y-2">
+          {[1, 2, 3, 4].map(i => (
+            <div
+              key={i}
+              className="h-10 bg-[var(--color-hover-bg)] rounded animate-shimmer"
+            ></div>
+          ))}
+        </div>
+      </div>
+    );
+  }
+
+  if (error) {
+    return (
+      <div className="mx-4 px-4 py-3 rounded-l-none bg-[rgba(224,108,117,0.1)] border-l-4 border-l-[var(--color-syntax-variable)] text-[var(--color-syntax-variable)] font-mono text-[13px]">
+        {error}
+      </div>
+    );
+  }
+
+  return (
+    <>
+      <SEO title={author.name} description={author.bio || undefined} />
+      <div className="w-full max-w-3xl mx-auto">
+        {/* Profile */}
+        <div className="flex items-start gap-4 px-4 py-4 mb-6 bg-[var(--color-sidebar-bg)] border border-[var(--color-hover-bg)] rounded">
+          {author.avatar_url && (
+            <img
+              src={author.avatar_url}
+              alt={author.name}
+              className="w-16 h-16 rounded-full object-cover shrink-0"
+            />
+          )}
+          <div className="flex-1 space-y-2">
+            <div className="flex items-center justify-between gap-3">
+              <h1 className="text-xl font-bold font-sans text-[#abb2bf]">
+                {author.name}
+              </h1>
+              <a
+                href={`/api/v1/authors/${author.handle}/rss.xml`}
+                className="text-[#5c6370] hover:text-[#61afef]"
+                title="RSS feed"
+              >
+                <BsRss className="w-4 h-4" />
+              </a>
+            </div>
+            {author.bio && (
+              <p className="text-[13px] text-[var(--color-text-secondary)]">
+                {author.bio}
+              </p>
+            )}
+            {author.links?.length > 0 && (
+              <div className="flex flex-wrap gap-3 text-[12px] font-mono">
+                {author.links.map(link => (
+                  <a
+                    key={link.url}
+                    href={link.url}
+                    target="_blank"
+                    rel="noopener noreferrer"
+                    className="text-[#61afef] hover:underline"
+                  >
+                    {link.name}
+                  </a>
+                ))}
+              </div>
+            )}
+          </div>
+        </div>
+
+        {/* Posts */}
+        {posts.length > 0 ? (
+          <div className="border border-[var(--color-hover-bg)] rounded overflow-hidden bg-[var(--color-sidebar-bg)]">
+            {posts.map(post => (
+              <Link
+                key={post.id}
+                to={`/posts/${post.slug}`}
+                className="group flex items-center gap-3 px-4 py-3 no-underline transition-all bg-transparent border-l-2 border-l-transparent border-b border-b-[#282c34] last:border-b-0 hover:bg-[#2c313a] hover:border-l-[#61afef]"
+              >
+                <span className="text-[11px] font-mono text-[#5c6370] min-w-[90px] shrink-0">
+                  {formatDate(post.published_at || post.created_at)}
+                </span>
+                <BsFileText className="w-3.5 h-3.5 text-[#5c6370] group-hover:text-[#61afef] transition-colors shrink-0" />
+                <span className="flex-1 text-[14px] font-sans text-[#abb2bf] group-hover:text-[#61afef] transition-colors">
+                  {post.title}
+                </span>
+                <BsChevronRight className="w-3 h-3 text-[#5c6370] opacity-0 group-hover:opacity-100 transition-all group-hover:translate-x-0.5" />
+              </Link>
+            ))}
+          </div>
+        ) : (
+          <p className="py-16 text-center text-[13px] text-[#5c6370]">
+            No posts published yet.
+          </p>
+        )}
+
+        {/* Pagination */}
+        {totalPost > LIMIT && (
+          <div className="flex justify-between mt-6 font-mono text-[13px]">
+            <button
+              disabled={offset === 0}
+              onClick={() => setOffset(Math.max(offset - LIMIT, 0))}
+              className="px-3 py-1 rounded bg-[#2c313a] text-[#abb2bf] disabled:opacity-40"
+            >
+              Newer
+            </button>
+            <button
+              disabled={offset + LIMIT >= totalPost}
+              onClick={() => setOffset(offset + LIMIT)}
+              className="px-3 py-1 rounded bg-[#2c313a] text-[#abb2bf] disabled:opacity-40"
+            >
+              Older
+            </button>
+          </div>
+        )}
+      </div>
+    </>
+  );
+};
+
+export default Author;
@@ -0,0 +1,112 @@
+import { useState } from "react";
+import { Link } from "react-router-dom";
+import { FiMail, FiKey, FiSend } from "react-icons/fi";
+import api from "@/services/api";
+
+const inputClass =
+  "w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-input-bg)] text-[var(--color-text-primary)] border border-[var(--color-input-border)] transition-all focus:outline-none focus:border-[var(--color-accent-primary)] focus:shadow-[0_0_0_3px_rgba(0,122,204,0.1)] placeholder:text-[var(--color-text-muted)]";
+
+const labelClass =
+  "flex items-center gap-2 text-sm font-medium font-sans text-[var(--color-text-secondary)]";
+
+const ForgotPassword = () => {
+  const [email, setEmail] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [message, setMessage] = useState("");
+  const [error, setError] = useState("");
+
+  const handleSubmit = async e => {
+    e.preventDefault();
+    setLoading(true);
+    setError("");
+
+    try {
+      const response = await api.post("/auth/forgot-password", { email });
+      setMessage(response.data.message);
+    } catch (err) {
+      setError(
+        err.response?.data?.error ||
+          "Could not send the reset link. Please try again."
+      );
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <>
+      <title>Forgot password</title>
+      <div className="flex items-center justify-center min-h-full py-8">
+        <div className="w-full max-w-md space-y-0">
+          {/* Header */}
+          <div className="text-center space-y-2 p-6 rounded-t bg-[var(--color-sidebar-bg)] border border-[var(--color-panel-border)] border-b-0">
+            <div className="w-12 h-12 mx-auto flex items-center justify-center rounded-full mb-2 bg-[var(--color-active-bg)] text-[var(--color-accent-primary)] text-xl">
+              <FiKey />
+            </div>
+            <h1 className="text-2xl font-bold font-sans text-[var(--color-text-primary)]">
+              Forgot password
+            </h1>
+          </div>
+
+          {/* Form */}
+          <form
+            onSubmit={handleSubmit}
+            className="space-y-4 p-6 bg-[var(--color-sidebar-bg)] border border-[var(--color-panel-border)] border-t-0 rounded-b"
+          >
+            {error && (
+              <div className="px-3 py-2 rounded text-sm font-sans bg-[rgba(220,38,38,0.1)] text-[#fca5a5] border border-[rgba(220,38,38,0.3)]">
+                {error}
+              </div>
+            )}
+
+            {message ? (
+              <p className="text-sm font-sans text-[var(--color-text-secondary)]">
+                {message}
+              </p>
+            ) : (
+              <>
+                <div className="space-y-1.5">
+                  <label htmlFor="email" className={labelClass}>
+                    <FiMail className="text-sm" />
+                    <span>Email Address</span>
+                  </label>
+                  <input
+                    id="email"
+                    name="email"
+                    type="email"
+                    required
+                    value={email}
+                    onChange={e => setEmail(e.target.value)}
+                    autoComplete="email"
+                    className={inputClass}
+                    placeholder="you@example.com"
+                  />
+                </div>
+
+                <button
+                  type="submit"
+                  disabled={loading}
+                  className="w-full inline-flex items-center justify-center gap-2 px-4 py-2.5 rounded transition-all text-sm font-medium font-sans bg-[var(--color-accent-primary)] text-white border border-[var(--color-accent-primary)] hover:bg-[var(--color-accent-hover)] hover:border-[var(--color-accent-hover)] disabled:opacity-50 disabled:cursor-not-allowed"
+                >
+                  <FiSend />
+                  <span>{loading ? "Sending..." : "Send Reset Link"}</span>
+                </button>
+              </>
+            )}
+
+            <p className="text-center text-sm font-sans">
+              <Link
+                to="/login"
+                className="text-[var(--color-accent-primary)] hover:underline"
+              >
+                Back to sign in
+              </Link>
+            </p>
+          </form>
+        </div>
+      </div>
+    </>
+  );
+};
+
+export default ForgotPassword;
@@ -38,7 +38,7 @@ const Home = () => {
   useEffect(() => {
     setLoading(true);
     api
-      .get("/posts?limit=5&order_by=created_at&sort=DESC")
+      .get("/posts?limit=5&order_by=published_at&sort=DESC")
       .then(res => {
         setLatestPosts(res.data?.posts || []);
       })
@@ -344,7 +344,7 @@ const Home = () => {
                   </span>
                 </div>
                 <span className="text-[10px] text-[var(--color-text-secondary)] font-mono shrink-0 ml-2">
-                  ({post.created_at ? post.created_at.slice(0, 10) : ""})
+                  ({post.published_at ? post.published_at.slice(0, 10) : ""})
                 </span>
               </Link>
             ))}
@@ -1,12 +1,19 @@
 import { useState } from "react";
-import { useNavigate } from "react-router-dom";
-import { FiMail, FiLock, FiLogIn } from "react-icons/fi";
+import { Link, useNavigate } from "react-router-dom";
+import { FiMail, FiLock, FiLogIn, FiShield, FiKey } from "react-icons/fi";
 import api from "@/services/api";
 import { setAuthTokens } from "@/utils/auth";
 
+// Passkeys need a browser that can read WebAuthn options from JSON.
+const passkeysSupported =
+  typeof window !== "undefined" &&
+  typeof window.PublicKeyCredential?.parseRequestOptionsFromJSON === "function";
+
 const Login = () => {
   const [email, setEmail] = useState("");
   const [password, setPassword] = useState("");
+  const [mfaToken, setMfaToken] = useState("");
+  const [code, setCode] = useState("");
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState("");
   const navigate = useNavigate();
@@ -17,17 +24,57 @@ const Login = () => {
     setError("");
 
     try {
-      const response = await api.post("/auth/login", { email, password });
+      const response = mfaToken
+        ? await api.post("/auth/login/2fa", { mfa_token: mfaToken, code })
+        : await api.post("/auth/login", { email, password });
+      if (response.data.mfa_required) {
+        setMfaToken(response.data.mfa_token);
+        return;
+      }
       const { access_token, refresh_token } = response.data;
       setAuthTokens({ access_token, refresh_token });
       navigate("/");
     } catch (err) {
+      // The challenge expired, start over with the password.
+      if (mfaToken && err.response?.status === 401) {
+        setMfaToken("");
+        setCode("");
+      }
       setError(err.response?.data?.error || "Login failed. Please try again.");
     } finally {
       setLoading(false);
     }
   };
 
+  const handlePasskeyLogin = async () => {
+    setLoading(true);
+    setError("");
+
+    try {
+      const begin = await api.post("/auth/webauthn/login/begin");
+      const { session_id, options } = begin.data;
+      const credential = await navigator.credentials.get({
+        publicKey: PublicKeyCredential.parseRequestOptionsFromJSON(
+          options.publicKey
+        )
+      });
+      const response = await api.post("/auth/webauthn/login/finish", {
+        session_id,
+        credential: credential.toJSON()
+      });
+      const { access_token, refresh_token } = response.data;
+      setAuthTokens({ access_token, refresh_token });
+      navigate("/");
+    } catch (err) {
+      setError(
+        err.response?.data?.error ||
+          "Passkey sign in failed or was cancelled. Please try again."
+      );
+    } finally {
+      setLoading(false);
+    }
+  };
+
   return (
     <>
       <title>Login</title>
@@ -68,49 +115,75 @@ const Login = () => {
               </div>
             )}
 
-            {/* Email Field */}
-            <div className="space-y-1.5">
-              <label
-                htmlFor="email"
-                className="flex items-center gap-2 text-sm font-medium font-sans text-[var(--color-text-secondary)]"
-              >
-                <FiMail className="text-sm" />
-                <span>Email Address</span>
-              </label>
-              <input
-                id="email"
-                name="email"
-                type="email"
-                required
-                value={email}
-                onChange={e => setEmail(e.target.value)}
-                autoComplete="email"
-                className="w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-input-bg)] text-[var(--color-text-primary)] border border-[var(--color-input-border)] transition-all focus:outline-none focus:border-[var(--color-accent-primary)] focus:shadow-[0_0_0_3px_rgba(0,122,204,0.1)] placeholder:text-[var(--color-text-muted)]"
-                placeholder="you@example.com"
-              />
-            </div>
+            {mfaToken ? (
+              /* Two-factor Code Field */
+              <div className="space-y-1.5">
+                <label
+                  htmlFor="code"
+                  className="flex items-center gap-2 text-sm font-medium font-sans text-[var(--color-text-secondary)]"
+                >
+                  <FiShield className="text-sm" />
+                  <span>Authentication Code</span>
+                </label>
+                <input
+                  id="code"
+                  name="code"
+                  required
+                  autoFocus
+                  value={code}
+                  onChange={e => setCode(e.target.value)}
+                  autoComplete="one-time-code"
+                  className="w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-input-bg)] text-[var(--color-text-primary)] border border-[var(--color-input-border)] transition-all focus:outline-none focus:border-[var(--color-accent-primary)] focus:shadow-[0_0_0_3px_rgba(0,122,204,0.1)] placeholder:text-[var(--color-text-muted)]"
+                  placeholder="123456 or a recovery code"
+                />
+              </div>
+            ) : (
+              <>
+                {/* Email Field */}
+                <div className="space-y-1.5">
+                  <label
+                    htmlFor="email"
+                    className="flex items-center gap-2 text-sm font-medium font-sans text-[var(--color-text-secondary)]"
+                  >
+                    <FiMail className="text-sm" />
+                    <span>Email Address</span>
+                  </label>
+                  <input
+                    id="email"
+                    name="email"
+                    type="email"
+                    required
+                    value={email}
+                    onChange={e => setEmail(e.target.value)}
+                    autoComplete="email"
+                    className="w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-input-bg)] text-[var(--color-text-primary)] border border-[var(--color-input-border)] transition-all focus:outline-none focus:border-[var(--color-accent-primary)] focus:shadow-[0_0_0_3px_rgba(0,122,204,0.1)] placeholder:text-[var(--color-text-muted)]"
+                    placeholder="you@example.com"
+                  />
+                </div>
 
-            {/* Password Field */}
-            <div className="space-y-1.5">
-              <label
-                htmlFor="password"
-                className="flex items-center gap-2 text-sm font-medium font-sans text-[var(--color-text-secondary)]"
-              >
-                <FiLock className="text-sm" />
-                <span>Password</span>
-              </label>
-              <input
-                id="password"
-                name="password"
-                type="password"
-                required
-                value={password}
-                onChange={e => setPassword(e.target.value)}
-                autoComplete="current-password"
-                className="w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-input-bg)] text-[var(--color-text-primary)] border border-[var(--color-input-border)] transition-all focus:outline-none focus:border-[var(--color-accent-primary)] focus:shadow-[0_0_0_3px_rgba(0,122,204,0.1)] placeholder:text-[var(--color-text-muted)]"
-                placeholder="••••••••"
-              />
-            </div>
+                {/* Password Field */}
+                <div className="space-y-1.5">
+                  <label
+                    htmlFor="password"
+                    className="flex items-center gap-2 text-sm font-medium font-sans text-[var(--color-text-secondary)]"
+                  >
+                    <FiLock className="text-sm" />
+                    <span>Password</span>
+                  </label>
+                  <input
+                    id="password"
+                    name="password"
+                    type="password"
+                    required
+                    value={password}
+                    onChange={e => setPassword(e.target.value)}
+                    autoComplete="current-password"
+                    className="w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-input-bg)] text-[var(--color-text-primary)] border border-[var(--color-input-border)] transition-all focus:outline-none focus:border-[var(--color-accent-primary)] focus:shadow-[0_0_0_3px_rgba(0,122,204,0.1)] placeholder:text-[var(--color-text-muted)]"
+                    placeholder="••••••••"
+                  />
+                </div>
+              </>
+            )}
 
             {/* Submit Button */}
             <button
@@ -145,10 +218,31 @@ const Login = () => {
               ) : (
                 <>
                   <FiLogIn />
-                  <span>Sign In</span>
+                  <span>{mfaToken ? "Verify" : "Sign In"}</span>
                 </>
               )}
             </button>
+
+            {passkeysSupported && !mfaToken && (
+              <button
+                type="button"
+                onClick={handlePasskeyLogin}
+                disabled={loading}
+                className="w-full inline-flex items-center justify-center gap-2 px-4 py-2.5 rounded transition-all text-sm font-medium font-sans bg-transparent text-[var(--color-text-primary)] border border-[var(--color-input-border)] hover:border-[var(--color-accent-primary)] disabled:opacity-50 disabled:cursor-not-allowed"
+              >
+                <FiKey />
+                <span>Sign in with a passkey</span>
+              </button>
+            )}
+
+            <p className="text-center text-sm font-sans">
+              <Link
+                to="/forgot-password"
+                className="text-[var(--color-accent-primary)] hover:underline"
+              >
+                Forgot your password?
+              </Link>
+            </p>
           </form>
         </div>
       </div>
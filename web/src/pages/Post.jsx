@@ -100,9 +100,63 @@ const SocialShare = ({ url, title }) => (
   </div>
 );
 
-const Post = () => {
-  const { slug } = useParams();
+// Unlock tokens of password protected posts live for the browser session only.
+const postTokenKey = slug => `post-token:${slug}`;
+
+const UnlockForm = ({ slug, onUnlock }) => {
+  const [password, setPassword] = useState("");
+  const [error, setError] = useState("");
+  const [submitting, setSubmitting] = useState(false);
+
+  const handleSubmit = async e => {
+    e.preventDefault();
+    try {
+      setSubmitting(true);
+      setError("");
+      const response = await api.post(`/posts/${slug}/unlock`, { password });
+      sessionStorage.setItem(postTokenKey(slug), response.data.token);
+      onUnlock();
+    } catch (err) {
+      setError(err.response?.data?.error || "Failed to unlock post");
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <form onSubmit={handleSubmit} className="max-w-sm space-y-3">
+      <p className="text-sm font-sans text-[var(--color-text-secondary)]">
+        This post is password protected. Enter the password to read it.
+      </p>
+      <input
+        type="password"
+        value={password}
+        onChange={e => setPassword(e.target.value)}
+        className="w-full px-3 py-2 rounded text-sm font-mono bg-[var(--color-sidebar-bg)] text-[var(--color-text-primary)] border border-[var(--color-hover-bg)] focus:outline-none focus:border-[var(--color-accent-primary)]"
+        aria-label="Post password"
+        required
+      />
+      {error && (
+        <p className="text-[13px] font-mono text-[var(--color-syntax-variable)]">
+          {error}
+        </p>
+      )}
+      <button
+        type="submit"
+        disabled={submitting}
+        className="px-4 py-2 rounded text-sm font-sans bg-[var(--color-accent-primary)] text-white disabled:opacity-50"
+      >
+        {submitting ? "Unlocking..." : "Unlock"}
+      </button>
+    </form>
+  );
+};
+
+// Post renders a published post by slug, or an unpublished one through a preview link.
+const Post = ({ preview = false }) => {
+  const { slug, token: previewToken } = useParams();
   const [post, setPost] = useState(null);
+  const [locked, setLocked] = useState(false);
   const [previousPost, setPreviousPost] = useState(null);
   const [nextPost, setNextPost] = useState(null);
   const [loading, setLoading] = useState(true);
@@ -111,13 +165,42 @@ const Post = () => {
   useScrollToHash();
 
   const fetchPost = useCallback(async () => {
+    if (preview) {
+      try {
+        setLoading(true);
+        const response = await api.get(`/previews/${previewToken}`);
+        setPost(response.data.post);
+      } catch (error) {
+        setError(
+          error.response?.data?.error ||
+            "This preview link is invalid or expired"
+        );
+      } finally {
+        setLoading(false);
+      }
+      return;
+    }
+
     if (!slug) return;
 
     try {
       setLoading(true);
-      const response = await api.get(`/posts/${slug}`);
-      const { post: currentPost, next_post, previous_post } = response.data;
+      const token = sessionStorage.getItem(postTokenKey(slug));
+      const response = await api.get(`/posts/${slug}`, {
+        headers: token ? { "X-Post-Token": token } : {}
+      });
+      const {
+        post: currentPost,
+        next_post,
+        previous_post,
+        locked: isLocked
+      } = response.data;
+      if (isLocked && token) {
+        // The token expired, ask for the password again.
+        sessionStorage.removeItem(postTokenKey(slug));
+      }
       setPost(currentPost);
+      setLocked(Boolean(isLocked));
       setPreviousPost(previous_post);
       setNextPost(next_post);
     } catch (error) {
@@ -125,7 +208,7 @@ const Post = () => {
     } finally {
       setLoading(false);
     }
-  }, [slug]);
+  }, [slug, preview, previewToken]);
 
   useEffect(() => {
     fetchPost();
@@ -235,6 +318,11 @@ const Post = () => {
         ogType="article"
       />
       <article className="w-full max-w-4xl mx-auto">
+        {preview && (
+          <div className="mb-6 px-4 py-2 rounded text-[13px] font-mono bg-[var(--color-hover-bg)] text-[var(--color-text-secondary)] border border-[var(--color-active-bg)]">
+            Preview: this post isn't published yet.
+          </div>
+        )}
         {/* Header */}
         <header className="space-y-5 pb-6 mb-8 border-b border-b-[var(--color-hover-bg)]">
           <h1 className="text-3xl font-bold leading-tight font-sans text-[var(--color-text-primary)] tracking-tight">
@@ -242,11 +330,25 @@ const Post = () => {
           </h1>
 
           <div className="flex flex-wrap items-center gap-2 text-[13px] font-mono text-[var(--color-text-secondary)]">
-            <span>{formatDate(post.created_at)}</span>
+            <span>{formatDate(post.published_at || post.created_at)}</span>
             <span>·</span>
             <span>{CalculateReadTime(post.content)}</span>
             <span>·</span>
-            <span>{post.author}</span>
+            <span>
+              {post.authors?.length
+                ? post.authors.map((a, i) => (
+                    <span key={a.id}>
+                      {i > 0 && ", "}
+                      <Link
+                        to={`/authors/${a.handle}`}
+                        className="hover:text-[var(--color-accent-primary)]"
+                      >
+                        {a.name}
+                      </Link>
+                    </span>
+                  ))
+                : post.author}
+            </span>
           </div>
 
           {/* Tags */}
@@ -267,106 +369,114 @@ const Post = () => {
         </header>
 
         {/* Content */}
-        <Suspense
-          fallback={
-            <div className="prose">
-              <div className="text-[13px] font-mono text-[var(--color-text-secondary)] animate-pulse">
-                Loading content...
+        {locked ? (
+          <UnlockForm slug={slug} onUnlock={fetchPost} />
+        ) : (
+          <Suspense
+            fallback={
+              <div className="prose">
+                <div className="text-[13px] font-mono text-[var(--color-text-secondary)] animate-pulse">
+                  Loading content...
+                </div>
               </div>
-            </div>
-          }
-        >
-          <div className="prose">
-            <Markdown
-              remarkPlugins={[remarkGfm]}
-              rehypePlugins={[
-                [
-                  rehypeHighlight,
-                  { detect: true, ignoreMissing: true, subset: false }
-                ],
-                [
-                  rehypeExternalLinks,
-                  {
-                    target: "_blank",
-                    rel: ["noopener", "noreferrer"]
+            }
+          >
+            <div className="prose">
+              <Markdown
+                remarkPlugins={[remarkGfm]}
+                rehypePlugins={[
+                  [
+                    rehypeHighlight,
+                    { detect: true, ignoreMissing: true, subset: false }
+                  ],
+                  [
+                    rehypeExternalLinks,
+                    {
+                      target: "_blank",
+                      rel: ["noopener", "noreferrer"]
+                    }
+                  ]
+                ]}
+                components={{
+                  h1: props => <HeadingRenderer level={1} {...props} />,
+                  h2: props => <HeadingRenderer level={2} {...props} />,
+                  h3: props => <HeadingRenderer level={3} {...props} />,
+                  h4: props => <HeadingRenderer level={4} {...props} />,
+                  h5: props => <HeadingRenderer level={5} {...props} />,
+                  h6: props => <HeadingRenderer level={6} {...props} />,
+                  pre: ({ children, ...props }) => {
+                    const codeContent = children?.props?.children || "";
+                    const codeString =
+                      typeof codeContent === "string"
+                        ? codeContent
+                        : String(codeContent);
+
+                    return (
+                      <pre {...props} className="group relative">
+                        <CopyCodeButton code={codeString.trim()} />
+                        {children}
+                      </pre>
+                    );
                   }
-                ]
-              ]}
-              components={{
-                h1: props => <HeadingRenderer level={1} {...props} />,
-                h2: props => <HeadingRenderer level={2} {...props} />,
-                h3: props => <HeadingRenderer level={3} {...props} />,
-                h4: props => <HeadingRenderer level={4} {...props} />,
-                h5: props => <HeadingRenderer level={5} {...props} />,
-                h6: props => <HeadingRenderer level={6} {...props} />,
-                pre: ({ children, ...props }) => {
-                  const codeContent = children?.props?.children || "";
-                  const codeString =
-                    typeof codeContent === "string"
-                      ? codeContent
-                      : String(codeContent);
-
-                  return (
-                    <pre {...props} className="group relative">
-                      <CopyCodeButton code={codeString.trim()} />
-                      {children}
-                    </pre>
-                  );
-                }
-              }}
-            >
-              {post.content}
-            </Markdown>
-          </div>
-        </Suspense>
-
-        {/* Social Share */}
-        <SocialShare url={postUrl} title={post.title} />
-
-        {/* Navigation */}
-        <nav className="pt-8 mt-8">
-          <div className="grid grid-cols-1 sm:grid-cols-2 gap-4">
-            {previousPost ? (
-              <Link
-                to={`/posts/${previousPost.slug}`}
-                className="group flex items-center gap-3 p-4 rounded no-underline transition-all min-h-[80px] bg-[var(--color-hover-bg)] border border-[var(--color-active-bg)] justify-start hover:bg-[var(--color-active-bg)] hover:border-[var(--color-accent-primary)]"
-                aria-label={`Previous post: ${previousPost.title}`}
-              >
-                <BsChevronLeft className="shrink-0 w-5 h-5 text-[var(--color-accent-primary)] transition-transform duration-200 group-hover:-translate-x-1" />
-                <div className="flex-1 min-w-0">
-                  <div className="text-[10px] font-semibold mb-1.5 text-[var(--color-text-secondary)] uppercase tracking-wider">
-                    Previous
-                  </div>
-                  <div className="font-medium text-[13px] leading-tight font-sans line-clamp-2 text-[var(--color-text-primary)]">
-                    {previousPost.title}
-                  </div>
-                </div>
-              </Link>
-            ) : (
-              <div></div>
-            )}
-
-            {nextPost ? (
-              <Link
-                to={`/posts/${nextPost.slug}`}
-                className="group flex items-center gap-3 p-4 rounded no-underline transition-all min-h-[80px] bg-[var(--color-hover-bg)] border border-[var(--color-active-bg)] justify-end text-right hover:bg-[var(--color-active-bg)] hover:border-[var(--color-accent-primary)]"
-                aria-label={`Next post: ${nextPost.title}`}
+                }}
               >
-                <div className="flex-1 min-w-0">
-                  <div className="text-[10px] font-semibold mb-1.5 text-[var(--color-text-secondary)] uppercase tracking-wider">
-                    Next
-                  </div>
-                  <div className="font-medium text-[13px] leading-tight font-sans line-clamp-2 text-[var(--color-text-primary)]">
-                    {nextPost.title}
-                  </div>
-                </div>
-                <BsChevronRight className="shrink-0 w-5 h-5 text-[var(--color-accent-primary)] transition-transform duration-200 group-hover:translate-x-1" />
-              </Link>
-            ) : (
-              <div></div>
-            )}
-          </div>
-        </nav>
+                {post.content}
+              </Markdown>
+            </div>
+          </Suspense>
+        )}
+
+        {!preview && (
+          <>
+            {/* Social Share */}
+            <SocialShare url={postUrl} title={post.title} />
+
+            {/* Navigation */}
+            <nav className="pt-8 mt-8">
+              <div className="grid grid-cols-1 sm:grid-cols-2 gap-4">
+                {previousPost ? (
+                  <Link
+                    to={`/posts/${previousPost.slug}`}
+                    className="group flex items-center gap-3 p-4 rounded no-underline transition-all min-h-[80px] bg-[var(--color-hover-bg)] border border-[var(--color-active-bg)] justify-start hover:bg-[var(--color-active-bg)] hover:border-[var(--color-accent-primary)]"
+                    aria-label={`Previous post: ${previousPost.title}`}
+                  >
+                    <BsChevronLeft className="shrink-0 w-5 h-5 text-[var(--color-accent-primary)] transition-transform duration-200 group-hover:-translate-x-1" />
+                    <div className="flex-1 min-w-0">
+                      <div className="text-[10px] font-semibold mb-1.5 text-[var(--color-text-secondary)] uppercase tracking-wider">
+                        Previous
+                      </div>
+                      <div className="font-medium text-[13px] leading-tight font-sans line-clamp-2 text-[var(--color-text-primary)]">
+                        {previousPost.title}
+                      </div>
+                    </div>
+                  </Link>
+                ) : (
+                  <div></div>
+                )}
+
+                {nextPost ? (
+                  <Link
+                    to={`/posts/${nextPost.slug}`}
+                    className="group flex items-center gap-3 p-4 rounded no-underline transition-all min-h-[80px] bg-[var(--color-hover-bg)] border border-[var(--color-active-bg)] justify-end text-right hover:bg-[var(--color-active-bg)] hover:border-[var(--color-accent-primary)]"
+                    aria-label={`Next post: ${nextPost.title}`}
+                  >
+                    <div className="flex-1 min-w-0">
+                      <div className="text-[10px] font-semibold mb-1.5 text-[var(--color-text-secondary)] uppercase tracking-wider">
+                        Next
+                      </div>
+                      <div className="font-medium text-[13px] leading-tight font-sans line-clamp-2 text-[var(--color-text-primary)]">
+                        {nextPost.title}
+                      </div>
+                    </div>
+                    <BsChevronRight className="shrink-0 w-5 h-5 text-[var(--color-accent-primary)] transition-transform duration-200 group-hover:translate-x-1" />
+                  </Link>
+                ) : (
+                  <div></div>
+                )}
+              </div>
+            </nav>
+          </>
+        )}
       </article>
     </>
   );
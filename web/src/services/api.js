@@ -1,5 +1,10 @@
 import axios from "axios";
-import { clearAuthTokens, getAuthTokens, setAuthTokens } from "@/utils/auth";
+import {
+  clearAuthTokens,
+  getAuthTokens,
+  getCSRFToken,
+  setAuthTokens
+} from "@/utils/auth";
 
 const api = axios.create({
   baseURL: "/api/v1",
@@ -13,10 +18,17 @@ api.interceptors.request.use(
   config => {
     const { access_token, refresh_token } = getAuthTokens();
 
+    // In cookie mode the browser sends the tokens, state changing requests
+    // have to echo the CSRF token.
+    const csrfToken = getCSRFToken();
+    if (csrfToken && !["get", "head", "options"].includes(config.method)) {
+      config.headers["X-CSRF-Token"] = csrfToken;
+    }
+
     // Attach tokens only for /auth routes
     if (config.url && config.url.startsWith("/auth")) {
-      // Handle the refresh token separately for the refresh endpoint
-      if (config.url === "/auth/refresh") {
+      // Refreshing and signing out take the refresh token instead
+      if (config.url === "/auth/refresh" || config.url === "/auth/logout") {
         if (refresh_token) {
           config.headers["Authorization"] = `Bearer ${refresh_token}`;
         }
@@ -62,14 +74,17 @@ api.interceptors.response.use(
       error.response &&
       error.response.status === 401 &&
       !originalRequest._retry &&
-      originalRequest.url !== "/auth/refresh" // Don't retry refresh token requests
+      originalRequest.url !== "/auth/refresh" && // Don't retry refresh token requests
+      originalRequest.url !== "/auth/logout"
     ) {
       if (isRefreshing) {
         return new Promise(function (resolve, reject) {
           failedQueue.push({ resolve, reject });
         })
           .then(token => {
-            originalRequest.headers["Authorization"] = `Bearer ${token}`;
+            if (token) {
+              originalRequest.headers["Authorization"] = `Bearer ${token}`;
+            }
             return api(originalRequest);
           })
           .catch(err => Promise.reject(err));
@@ -80,24 +95,32 @@ api.interceptors.response.use(
 
       try {
         const { refresh_token } = getAuthTokens();
-        if (!refresh_token) {
+        const csrfToken = getCSRFToken();
+        if (!refresh_token && !csrfToken) {
           throw new Error("No refresh token available");
         }
 
+        // In cookie mode the refresh cookie goes along by itself.
+        const headers = { "Content-Type": "application/json" };
+        if (refresh_token) {
+          headers["Authorization"] = `Bearer ${refresh_token}`;
+        } else {
+          headers["X-CSRF-Token"] = csrfToken;
+        }
         const response = await axios.post("/api/v1/auth/refresh", "", {
-          headers: {
-            "Content-Type": "application/json",
-            Authorization: `Bearer ${refresh_token}`
-          }
+          headers
         });
 
         const { access_token, refresh_token: newRefreshToken } = response.data;
         setAuthTokens({ access_token, refresh_token: newRefreshToken });
 
-        api.defaults.headers.common["Authorization"] = `Bearer ${access_token}`;
+        if (access_token) {
+          api.defaults.headers.common["Authorization"] =
+            `Bearer ${access_token}`;
+          originalRequest.headers["Authorization"] = `Bearer ${access_token}`;
+        }
         processQueue(null, access_token);
 
-        originalRequest.headers["Authorization"] = `Bearer ${access_token}`;
         return api(originalRequest);
       } catch (err) {
         processQueue(err, null);
@@ -1,3 +1,6 @@
+// Cookie holding the CSRF token when the server runs in cookie mode.
+const CSRF_COOKIE = "blog_csrf";
+
 export const getAuthTokens = () => {
   const tokens = localStorage.getItem("authTokens");
   return tokens
@@ -6,6 +9,12 @@ export const getAuthTokens = () => {
 };
 
 export const setAuthTokens = ({ access_token, refresh_token }) => {
+  // In cookie mode the server keeps the tokens in HttpOnly cookies and
+  // leaves them out of its responses.
+  if (!access_token) {
+    localStorage.removeItem("authTokens");
+    return;
+  }
   localStorage.setItem(
     "authTokens",
     JSON.stringify({ access_token, refresh_token })
@@ -14,4 +23,21 @@ export const setAuthTokens = ({ access_token, refresh_token }) => {
 
 export const clearAuthTokens = () => {
   localStorage.removeItem("authTokens");
+  document.cookie = `${CSRF_COOKIE}=; Max-Age=0; Path=/; SameSite=Strict`;
+};
+
+// getCSRFToken returns the CSRF token of a cookie session, sent back in the
+// X-CSRF-Token header of state changing requests.
+export const getCSRFToken = () => {
+  const cookie = document.cookie
+    .split("; ")
+    .find(c => c.startsWith(`${CSRF_COOKIE}=`));
+  return cookie
+    ? decodeURIComponent(cookie.slice(CSRF_COOKIE.length + 1))
+    : null;
 };
+
+// isSignedIn reports whether there is a session, held in tokens or, in cookie
+// mode, in cookies marked by the readable CSRF cookie.
+export const isSignedIn = () =>
+  Boolean(getAuthTokens().access_token || getCSRFToken());